@@ -1,7 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import { ChevronRight, ChevronDown, Hash, Type, AlignLeft, Calendar, CheckSquare, Box, Code, PieChart } from 'lucide-react';
 import { Field } from '../types';
-import { getColorForType } from '../utils';
+import { getColorForType, getChildFields } from '../utils';
 
 interface SchemaNodeProps {
   field: Field;
@@ -31,7 +31,8 @@ export const SchemaNode: React.FC<SchemaNodeProps> = ({
   collapseTrigger = 0
 }) => {
   const [isOpen, setIsOpen] = useState(false);
-  const hasChildren = field.nested_fields && field.nested_fields.length > 0;
+  const children = getChildFields(field);
+  const hasChildren = children.length > 0;
   const isObject = field.inferred_type === 'object';
   
   // Auto-expand top level objects if they aren't too deep
@@ -84,6 +85,7 @@ export const SchemaNode: React.FC<SchemaNodeProps> = ({
           <span className={`text-[10px] px-2 py-0.5 rounded-full border flex items-center gap-1 font-medium uppercase tracking-wider ${typeColorClass}`}>
             <TypeIcon type={field.inferred_type} />
             {field.inferred_type}
+            {field.array_items && field.array_items.inferred_type !== 'unknown' && `<${field.array_items.inferred_type}>`}
           </span>
 
           {field.types.length > 1 && (
@@ -123,7 +125,7 @@ export const SchemaNode: React.FC<SchemaNodeProps> = ({
 
       {hasChildren && isOpen && (
         <div className="animate-in slide-in-from-top-1 duration-200">
-          {field.nested_fields!.map((child, idx) => (
+          {children.map((child, idx) => (
             <SchemaNode 
               key={idx} 
               field={child} 
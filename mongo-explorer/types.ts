@@ -9,6 +9,7 @@ export interface Field {
   inferred_type: string;
   presence_percent: number;
   nested_fields?: Field[];
+  array_items?: Field;
 }
 
 export interface Collection {
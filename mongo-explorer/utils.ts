@@ -1,3 +1,5 @@
+import { Field } from './types';
+
 export const formatBytes = (bytes: number, decimals = 2) => {
   if (!+bytes) return '0 Bytes';
 
@@ -29,3 +31,12 @@ export const getColorForType = (type: string) => {
     default: return 'text-gray-600 bg-gray-50 border-gray-200';
   }
 };
+
+// Child fields of a node: nested object fields plus the fields of array elements
+export const getChildFields = (field: Field): Field[] => {
+  const children = [...(field.nested_fields ?? [])];
+  for (let items = field.array_items; items; items = items.array_items) {
+    children.push(...(items.nested_fields ?? []));
+  }
+  return children;
+};
@@ -2,7 +2,7 @@ import React, { useState } from 'react';
 import { Database, HardDrive, FileText, Database as DbIcon, Edit2, Search, ArrowRight, Layers, Table, Info, Hash, PieChart, Activity, Link, ArrowDownAZ, ArrowDownWideNarrow, Maximize2, Minimize2, FileCode, Copy, Check } from 'lucide-react';
 import { BarChart, Bar, XAxis, YAxis, Tooltip, ResponsiveContainer, Cell } from 'recharts';
 import { ClusterScan, Database as IDatabase, Collection, ViewLevel, Field } from '../types';
-import { formatBytes, formatNumber, getColorForType } from '../utils';
+import { formatBytes, formatNumber, getColorForType, getChildFields } from '../utils';
 import { SizeChart } from '../components/Charts';
 import { SchemaNode } from '../components/Schema';
 
@@ -84,7 +84,14 @@ const generateGoStruct = (collectionName: string, fields: Field[]): string => {
        else if (t === 'date') typeStr = 'time.Time';
        else if (['int32', 'int64', 'number'].includes(t)) typeStr = 'int64';
        else if (t === 'double') typeStr = 'float64';
-       else if (t === 'array') typeStr = '[]interface{}'; 
+       else if (t === 'array') {
+           const items = field.array_items;
+           if (items && items.nested_fields && items.nested_fields.length > 0) {
+               typeStr = `[]struct {\n${processFields(items.nested_fields, indent + '    ')}${indent}}`;
+           } else {
+               typeStr = '[]interface{}';
+           }
+       }
        else if (t === 'object') {
            if (field.nested_fields && field.nested_fields.length > 0) {
                typeStr = `struct {\n${processFields(field.nested_fields, indent + '    ')}${indent}}`;
@@ -119,8 +126,9 @@ const countFieldMatches = (fields: Field[], term: string): number => {
   let count = 0;
   for (const f of fields) {
     if (f.path.toLowerCase().includes(term)) count++;
-    if (f.nested_fields && f.nested_fields.length > 0) {
-      count += countFieldMatches(f.nested_fields, term);
+    const children = getChildFields(f);
+    if (children.length > 0) {
+      count += countFieldMatches(children, term);
     }
   }
   return count;
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mongo-scanner/internal/advisor"
+	"mongo-scanner/internal/loader"
+	"mongo-scanner/internal/report"
+	"mongo-scanner/internal/types"
+)
+
+var (
+	adviseInput       string
+	adviseOutput      string
+	adviseFormat      string
+	adviseMinPresence float64
+	adviseFailOn      string
+)
+
+// adviseCmd cross-references index definitions with field statistics
+var adviseCmd = &cobra.Command{
+	Use:   "advise",
+	Short: "Suggest index improvements from a scan report",
+	Long: `Advise cross-references the index definitions of a scan report with the
+observed field statistics and flags:
+- Indexes on fields with low presence (suggest sparse or partial indexes)
+- Indexes on fields that no longer appear in the sample
+- Redundant indexes that are a prefix of another index
+- Inferred reference fields without an index
+- TTL indexes on non-date fields`,
+	RunE: runAdvise,
+}
+
+func init() {
+	adviseCmd.Flags().StringVar(&adviseInput, "input", "", "Scan report to analyze (JSON or YAML, required)")
+	adviseCmd.Flags().StringVar(&adviseOutput, "output", "-", "Output file path (- for stdout)")
+	adviseCmd.Flags().StringVar(&adviseFormat, "format", "markdown", "Output format: text, json, markdown, junit, or sarif")
+	adviseCmd.Flags().Float64Var(&adviseMinPresence, "min-presence", 50, "Presence percentage below which indexed fields are reported")
+	adviseCmd.Flags().StringVar(&adviseFailOn, "fail-on", "", "Exit with an error when a finding of this severity or higher exists: info, warning, or error")
+
+	adviseCmd.MarkFlagRequired("input")
+
+	rootCmd.AddCommand(adviseCmd)
+}
+
+func runAdvise(cmd *cobra.Command, args []string) error {
+	reportFormat := report.Format(strings.ToLower(adviseFormat))
+	if !isValidReportFormat(reportFormat) {
+		return fmt.Errorf("invalid format: %s. Valid formats: %v", adviseFormat, report.ValidFormats())
+	}
+	if adviseFailOn != "" && !isValidSeverity(adviseFailOn) {
+		return fmt.Errorf("invalid --fail-on severity: %s", adviseFailOn)
+	}
+
+	result, err := loader.LoadFile(adviseInput)
+	if err != nil {
+		return err
+	}
+
+	findings := advisor.Advise(result, advisor.Options{MinPresence: adviseMinPresence})
+
+	err = writeOutput(adviseOutput, func(w io.Writer) error {
+		return report.WriteFindings(w, "Index Advisor", findings, reportFormat, types.Severity(adviseFailOn))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write advisor report: %w", err)
+	}
+
+	return checkFailOn(findings, adviseFailOn)
+}
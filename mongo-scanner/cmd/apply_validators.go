@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"mongo-scanner/internal/exporter"
+	"mongo-scanner/internal/loader"
+	"mongo-scanner/internal/logger"
+	"mongo-scanner/internal/scanner"
+	"mongo-scanner/internal/textdiff"
+	"mongo-scanner/internal/types"
+)
+
+var (
+	applyURI               string
+	applyInput             string
+	applyDBFilter          string
+	applyDryRun            bool
+	applyValidationLevel   string
+	applyValidationAction  string
+	applyRequiredThreshold float64
+	applyTimeout           int
+	applyVerbose           bool
+)
+
+// validationLevels and validationActions are the values accepted by collMod
+var (
+	validationLevels  = []string{"off", "moderate", "strict"}
+	validationActions = []string{"warn", "error"}
+)
+
+// applyValidatorsCmd installs $jsonSchema validators built from a scan report
+var applyValidatorsCmd = &cobra.Command{
+	Use:   "apply-validators",
+	Short: "Apply $jsonSchema validators from a scan report with collMod",
+	Long: `Apply-validators builds a $jsonSchema validator for each collection of a
+scan report, prints the diff against the collection's current validator and
+installs it with collMod. Use --dry-run to only print the diffs.`,
+	RunE: runApplyValidators,
+}
+
+func init() {
+	applyValidatorsCmd.Flags().StringVar(&applyURI, "uri", "", "MongoDB connection URI (required)")
+	applyValidatorsCmd.Flags().StringVar(&applyInput, "input", "", "Scan report to build validators from (JSON or YAML, required)")
+	applyValidatorsCmd.Flags().StringVar(&applyDBFilter, "db-filter", "", "Comma-separated database name patterns (regex supported)")
+	applyValidatorsCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Print the diffs without running collMod")
+	applyValidatorsCmd.Flags().StringVar(&applyValidationLevel, "validation-level", "moderate", "Validation level: off, moderate, or strict")
+	applyValidatorsCmd.Flags().StringVar(&applyValidationAction, "validation-action", "warn", "Validation action: warn or error")
+	applyValidatorsCmd.Flags().Float64Var(&applyRequiredThreshold, "required-threshold", 100, "Presence percentage at or above which fields are required")
+	applyValidatorsCmd.Flags().IntVar(&applyTimeout, "timeout", 60, "Timeout in seconds for reading and applying the validator of each collection")
+	applyValidatorsCmd.Flags().BoolVar(&applyVerbose, "verbose", false, "Enable verbose logging")
+
+	applyValidatorsCmd.MarkFlagRequired("uri")
+	applyValidatorsCmd.MarkFlagRequired("input")
+
+	rootCmd.AddCommand(applyValidatorsCmd)
+}
+
+func runApplyValidators(cmd *cobra.Command, args []string) error {
+	log := logger.NewLogger(applyVerbose)
+
+	if !slices.Contains(validationLevels, applyValidationLevel) {
+		return fmt.Errorf("invalid validation level: %s. Valid levels: %v", applyValidationLevel, validationLevels)
+	}
+	if !slices.Contains(validationActions, applyValidationAction) {
+		return fmt.Errorf("invalid validation action: %s. Valid actions: %v", applyValidationAction, validationActions)
+	}
+	if applyTimeout <= 0 {
+		return fmt.Errorf("invalid --timeout: %d", applyTimeout)
+	}
+
+	result, err := loader.LoadFile(applyInput)
+	if err != nil {
+		return err
+	}
+
+	var dbFilters []string
+	if applyDBFilter != "" {
+		dbFilters = strings.Split(applyDBFilter, ",")
+		for i := range dbFilters {
+			dbFilters[i] = strings.TrimSpace(dbFilters[i])
+		}
+	}
+
+	opts := types.ScanOptions{
+		URI:      applyURI,
+		Timeout:  time.Duration(applyTimeout) * time.Second,
+		DBFilter: dbFilters,
+		Verbose:  applyVerbose,
+	}
+
+	s, err := scanner.NewScanner(opts, log)
+	if err != nil {
+		return fmt.Errorf("failed to create scanner: %w", err)
+	}
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		defer cancel()
+		s.Close(ctx)
+	}()
+
+	dbNames := make([]string, 0, len(result.Databases))
+	for _, db := range result.Databases {
+		dbNames = append(dbNames, db.Name)
+	}
+	selected := s.FilterDatabases(dbNames)
+
+	applied, pending, unchanged, failed := 0, 0, 0, 0
+	for _, db := range result.Databases {
+		if !slices.Contains(selected, db.Name) {
+			continue
+		}
+
+		for _, coll := range db.Collections {
+			// Each collection gets the full timeout, however many came before
+			ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+			outcome := applyCollectionValidator(ctx, s, log, db.Name, coll)
+			cancel()
+
+			switch outcome {
+			case validatorApplied:
+				applied++
+			case validatorPending:
+				pending++
+			case validatorUnchanged:
+				unchanged++
+			case validatorFailed:
+				failed++
+			}
+		}
+	}
+
+	if applyDryRun {
+		log.Info("Dry run: %d validator(s) would change, %d unchanged", pending, unchanged)
+	} else {
+		log.Info("Validators applied: %d, unchanged: %d, failed: %d", applied, unchanged, failed)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d collection(s) failed", failed)
+	}
+	return nil
+}
+
+// validatorOutcome is the result of applying the validator of a collection
+type validatorOutcome int
+
+const (
+	validatorSkipped validatorOutcome = iota
+	validatorUnchanged
+	validatorPending
+	validatorApplied
+	validatorFailed
+)
+
+// applyCollectionValidator prints the validator diff of a collection and
+// applies the proposed validator unless this is a dry run
+func applyCollectionValidator(ctx context.Context, s *scanner.Scanner, log *logger.Logger, dbName string, coll types.Collection) validatorOutcome {
+	name := dbName + "." + coll.Name
+
+	current, err := s.GetValidator(ctx, dbName, coll.Name)
+	if err != nil {
+		log.Error("%v", err)
+		return validatorFailed
+	}
+	if !current.Exists {
+		log.Warn("Skipping %s: collection does not exist", name)
+		return validatorSkipped
+	}
+
+	proposed := exporter.BuildValidator(coll, applyRequiredThreshold)
+	diff, err := validatorDiff(name, current, proposed)
+	if err != nil {
+		log.Error("Failed to compare validators of %s: %v", name, err)
+		return validatorFailed
+	}
+	if diff == "" {
+		log.Info("%s: validator is up to date", name)
+		return validatorUnchanged
+	}
+
+	fmt.Fprint(os.Stdout, diff)
+
+	if applyDryRun {
+		return validatorPending
+	}
+
+	if err := s.ApplyValidator(ctx, dbName, coll.Name, proposed, applyValidationLevel, applyValidationAction); err != nil {
+		log.Error("%v", err)
+		return validatorFailed
+	}
+	log.Info("%s: validator applied", name)
+	return validatorApplied
+}
+
+// validatorDiff returns the diff between a collection's current validation
+// settings and the proposed ones, or an empty string when they match
+func validatorDiff(name string, current *scanner.ValidatorState, proposed map[string]interface{}) (string, error) {
+	var currentJSON string
+	if current.Validator != nil {
+		extJSON, err := bson.MarshalExtJSON(current.Validator, false, false)
+		if err != nil {
+			return "", err
+		}
+		currentJSON, err = canonicalJSON(extJSON)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	proposedRaw, err := json.Marshal(proposed)
+	if err != nil {
+		return "", err
+	}
+	proposedJSON, err := canonicalJSON(proposedRaw)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(textdiff.Unified(name+" (current)", name+" (proposed)", currentJSON, proposedJSON))
+
+	if level := current.ValidationLevel; level != applyValidationLevel {
+		fmt.Fprintf(&b, "%s: validationLevel %s -> %s\n", name, orNone(level), applyValidationLevel)
+	}
+	if action := current.ValidationAction; action != applyValidationAction {
+		fmt.Fprintf(&b, "%s: validationAction %s -> %s\n", name, orNone(action), applyValidationAction)
+	}
+
+	return b.String(), nil
+}
+
+// canonicalJSON re-indents JSON with object keys sorted so that validators
+// from the server and from the scan report compare line by line
+func canonicalJSON(data []byte) (string, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "", err
+	}
+
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
+// orNone returns "none" for an empty setting
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"mongo-scanner/internal/loader"
+	"mongo-scanner/internal/logger"
+	"mongo-scanner/internal/policy"
+	"mongo-scanner/internal/report"
+	"mongo-scanner/internal/scanner"
+	"mongo-scanner/internal/types"
+)
+
+var (
+	checkBaseline   string
+	checkInput      string
+	checkURI        string
+	checkDBFilter   string
+	checkMaxDocs    int
+	checkTimeout    int
+	checkVerbose    bool
+	checkPolicy     string
+	checkOutput     string
+	checkFormat     string
+	checkJUnit      string
+	checkSARIF      string
+	checkFailOnFlag string
+)
+
+// checkCmd gates schema changes against a baseline scan report
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check schema changes against a baseline scan report",
+	Long: `Check compares a scan report (--input) or a fresh scan of a cluster (--uri)
+to a committed baseline report and reports the changes flagged by a policy
+file, such as:
+- Removed fields that used to be present in most documents
+- Inferred type changes and new mixed-type fields
+- Fields required by a validator built from the baseline that are missing
+
+It exits with an error when a violation reaches the policy's fail_on
+severity, and writes JUnit XML and SARIF reports for CI systems.`,
+	RunE: runCheck,
+}
+
+func init() {
+	checkCmd.Flags().StringVar(&checkBaseline, "baseline", "", "Baseline scan report (JSON, YAML or CSV, required)")
+	checkCmd.Flags().StringVar(&checkInput, "input", "", "Scan report to check (JSON, YAML or CSV)")
+	checkCmd.Flags().StringVar(&checkURI, "uri", "", "MongoDB connection URI to scan and check instead of --input")
+	checkCmd.Flags().StringVar(&checkDBFilter, "db-filter", "", "Comma-separated database name patterns (regex supported) for --uri")
+	checkCmd.Flags().IntVar(&checkMaxDocs, "max-docs", 75000, "Maximum documents to sample per collection for --uri")
+	checkCmd.Flags().IntVar(&checkTimeout, "timeout", 10000, "Scan timeout in seconds for --uri")
+	checkCmd.Flags().BoolVar(&checkVerbose, "verbose", false, "Enable verbose logging")
+	checkCmd.Flags().StringVar(&checkPolicy, "policy", "", "Policy file (YAML); defaults to the built-in policy")
+	checkCmd.Flags().StringVar(&checkOutput, "output", "-", "Output file path (- for stdout)")
+	checkCmd.Flags().StringVar(&checkFormat, "format", "text", "Output format: text, json, markdown, junit, or sarif")
+	checkCmd.Flags().StringVar(&checkJUnit, "junit", "", "Also write a JUnit XML report to this path")
+	checkCmd.Flags().StringVar(&checkSARIF, "sarif", "", "Also write a SARIF report to this path")
+	checkCmd.Flags().StringVar(&checkFailOnFlag, "fail-on", "", "Override the policy's fail_on severity: info, warning, or error")
+
+	checkCmd.MarkFlagRequired("baseline")
+	checkCmd.MarkFlagsMutuallyExclusive("input", "uri")
+	checkCmd.MarkFlagsOneRequired("input", "uri")
+
+	rootCmd.AddCommand(checkCmd)
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	reportFormat := report.Format(strings.ToLower(checkFormat))
+	if !isValidReportFormat(reportFormat) {
+		return fmt.Errorf("invalid format: %s. Valid formats: %v", checkFormat, report.ValidFormats())
+	}
+	if checkFailOnFlag != "" && !isValidSeverity(checkFailOnFlag) {
+		return fmt.Errorf("invalid --fail-on severity: %s", checkFailOnFlag)
+	}
+
+	pol := policy.DefaultPolicy()
+	if checkPolicy != "" {
+		var err error
+		if pol, err = policy.LoadFile(checkPolicy); err != nil {
+			return err
+		}
+	}
+	failOn := string(pol.FailOn)
+	if checkFailOnFlag != "" {
+		failOn = checkFailOnFlag
+	}
+
+	baseline, err := loader.LoadFile(checkBaseline)
+	if err != nil {
+		return err
+	}
+
+	var current *types.ScanResult
+	if checkInput != "" {
+		current, err = loader.LoadFile(checkInput)
+	} else {
+		current, err = scanForCheck()
+	}
+	if err != nil {
+		return err
+	}
+
+	findings := policy.Check(baseline, current, pol)
+	rules := make([]report.Rule, 0, len(pol.Rules))
+	for _, rule := range pol.Rules {
+		rules = append(rules, report.Rule{ID: rule.ID, Description: rule.Description})
+	}
+
+	write := func(path string, format report.Format) error {
+		return writeOutput(path, func(w io.Writer) error {
+			switch format {
+			case report.FormatJUnit:
+				return report.WriteJUnit(w, "Schema Check", rules, findings, types.Severity(failOn))
+			case report.FormatSARIF:
+				return report.WriteSARIF(w, checkBaseline, rules, findings)
+			default:
+				return report.WriteFindings(w, "Schema Check", findings, format, types.Severity(failOn))
+			}
+		})
+	}
+	if err := write(checkOutput, reportFormat); err != nil {
+		return fmt.Errorf("failed to write check report: %w", err)
+	}
+	if checkJUnit != "" {
+		if err := write(checkJUnit, report.FormatJUnit); err != nil {
+			return fmt.Errorf("failed to write JUnit report: %w", err)
+		}
+	}
+	if checkSARIF != "" {
+		if err := write(checkSARIF, report.FormatSARIF); err != nil {
+			return fmt.Errorf("failed to write SARIF report: %w", err)
+		}
+	}
+
+	return checkFailOn(findings, failOn)
+}
+
+// scanForCheck scans the cluster at --uri
+func scanForCheck() (*types.ScanResult, error) {
+	log := logger.NewLogger(checkVerbose)
+
+	var dbFilters []string
+	if checkDBFilter != "" {
+		dbFilters = strings.Split(checkDBFilter, ",")
+		for i := range dbFilters {
+			dbFilters[i] = strings.TrimSpace(dbFilters[i])
+		}
+	}
+
+	opts := types.DefaultScanOptions()
+	opts.URI = checkURI
+	opts.Timeout = time.Duration(checkTimeout) * time.Second
+	opts.MaxDocs = checkMaxDocs
+	opts.DBFilter = dbFilters
+	opts.Verbose = checkVerbose
+
+	s, err := scanner.NewScanner(opts, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scanner: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	defer s.Close(ctx)
+
+	result, err := s.ScanAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("scan failed: %w", err)
+	}
+	return result, nil
+}
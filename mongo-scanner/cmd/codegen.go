@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"mongo-scanner/internal/codegen"
+	"mongo-scanner/internal/loader"
+	"mongo-scanner/internal/logger"
+)
+
+var (
+	codegenInput             string
+	codegenOutputDir         string
+	codegenRequiredThreshold float64
+
+	codegenZod         bool
+	codegenDriverTypes bool
+)
+
+// codegenCmd groups the code generation targets
+var codegenCmd = &cobra.Command{
+	Use:   "codegen",
+	Short: "Generate model code from a scan report",
+	Long: `Codegen reads a scan report and generates typed models for each
+collection. Fields whose presence is below --required-threshold, or that
+contain null values, are generated as optional.`,
+}
+
+// codegenGoCmd generates Go structs
+var codegenGoCmd = &cobra.Command{
+	Use:   "go",
+	Short: "Generate Go structs with bson and json tags",
+	Long: `Generate one Go package per database with one struct per collection.
+Nested objects become named structs, ObjectIds, dates and decimals use
+primitive.ObjectID, time.Time and primitive.Decimal128, optional fields are
+pointers with omitempty, and mixed fields use interface{} (numeric mixes are
+widened to int64 or float64).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCodegen(codegen.TargetGo, codegen.Options{})
+	},
+}
+
+// codegenTSCmd generates TypeScript interfaces and Zod schemas
+var codegenTSCmd = &cobra.Command{
+	Use:   "ts",
+	Short: "Generate TypeScript interfaces, optionally with Zod schemas",
+	Long: `Generate one TypeScript module per database with one interface per
+collection. Optional properties come from field presence, unions from the
+observed types, enums become string literal unions and nested objects become
+named interfaces. Interface names are claimed by collections first, in name
+order, so collisions are resolved the same way on every run.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCodegen(codegen.TargetTypeScript, codegen.Options{
+			Zod:         codegenZod,
+			DriverTypes: codegenDriverTypes,
+		})
+	},
+}
+
+// codegenMongooseCmd generates Mongoose schemas and models
+var codegenMongooseCmd = &cobra.Command{
+	Use:   "mongoose",
+	Short: "Generate Mongoose schemas and models",
+	Long: `Generate one JavaScript module per database with a Mongoose schema and
+model per collection. ObjectIds, decimals and dates map to
+Schema.Types.ObjectId, Schema.Types.Decimal128 and Date, nested objects become
+sub-schemas, and the collection's indexes are declared with schema.index().`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCodegen(codegen.TargetMongoose, codegen.Options{})
+	},
+}
+
+// codegenPydanticCmd generates Pydantic models
+var codegenPydanticCmd = &cobra.Command{
+	Use:   "pydantic",
+	Short: "Generate Pydantic models",
+	Long: `Generate one Python module per database with a Pydantic v2 model per
+collection. ObjectIds, dates and decimals map to PydanticObjectId (beanie),
+datetime and Decimal, keys that are not valid Python names get an alias, and
+the collection's indexes are listed as pymongo IndexModel objects.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCodegen(codegen.TargetPydantic, codegen.Options{})
+	},
+}
+
+func init() {
+	codegenTSCmd.Flags().BoolVar(&codegenZod, "zod", false, "Also generate a Zod schema for each interface")
+	codegenTSCmd.Flags().BoolVar(&codegenDriverTypes, "driver-types", false, "Use ObjectId, Decimal128, Binary and Date from the mongodb driver instead of strings")
+
+	codegenCmd.PersistentFlags().StringVar(&codegenInput, "input", "", "Scan report to generate code from (JSON or YAML, required)")
+	codegenCmd.PersistentFlags().StringVar(&codegenOutputDir, "output-dir", "./models", "Directory receiving the generated files")
+	codegenCmd.PersistentFlags().Float64Var(&codegenRequiredThreshold, "required-threshold", 100, "Presence percentage at or above which fields are required")
+
+	codegenCmd.MarkPersistentFlagRequired("input")
+
+	codegenCmd.AddCommand(codegenGoCmd)
+	codegenCmd.AddCommand(codegenTSCmd)
+	codegenCmd.AddCommand(codegenMongooseCmd)
+	codegenCmd.AddCommand(codegenPydanticCmd)
+	rootCmd.AddCommand(codegenCmd)
+}
+
+// runCodegen generates the files of a target into the output directory.
+// Target-specific options are passed in opts; the shared flags are filled in.
+func runCodegen(target codegen.Target, opts codegen.Options) error {
+	log := logger.NewLogger(false)
+
+	result, err := loader.LoadFile(codegenInput)
+	if err != nil {
+		return err
+	}
+
+	opts.RequiredThreshold = codegenRequiredThreshold
+	generator, err := codegen.NewGenerator(target, opts)
+	if err != nil {
+		return err
+	}
+
+	files, err := generator.Generate(result)
+	if err != nil {
+		return fmt.Errorf("code generation failed: %w", err)
+	}
+
+	if err := codegen.WriteFiles(codegenOutputDir, files); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		log.Info("Generated %s", filepath.Join(codegenOutputDir, f.Path))
+	}
+	return nil
+}
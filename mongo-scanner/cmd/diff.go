@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mongo-scanner/internal/diff"
+	"mongo-scanner/internal/loader"
+	"mongo-scanner/internal/report"
+	"mongo-scanner/internal/types"
+)
+
+var (
+	diffOutput            string
+	diffFormat            string
+	diffTypeThreshold     float64
+	diffPresenceThreshold float64
+	diffGrowthThreshold   float64
+	diffFailOnBreaking    bool
+)
+
+// diffCmd compares two scan reports
+var diffCmd = &cobra.Command{
+	Use:   "diff <old-report> <new-report>",
+	Short: "Compare two scan reports",
+	Long: `Diff compares two scan reports (JSON, YAML or CSV, optionally .gz or .zst
+compressed) and reports:
+- Added and removed databases, collections, fields and indexes
+- Inferred type changes and type distribution shifts
+- Field presence changes
+- Document count and size growth`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffOutput, "output", "-", "Output file path (- for stdout)")
+	diffCmd.Flags().StringVar(&diffFormat, "format", "text", "Output format: text, json, or markdown")
+	diffCmd.Flags().Float64Var(&diffTypeThreshold, "type-threshold", 5, "Shift of a type's frequency, in percentage points, at or above which it is reported")
+	diffCmd.Flags().Float64Var(&diffPresenceThreshold, "presence-threshold", 5, "Change of a field's presence, in percentage points, at or above which it is reported")
+	diffCmd.Flags().Float64Var(&diffGrowthThreshold, "growth-threshold", 10, "Relative change of document counts and sizes, in percent, at or above which it is reported")
+	diffCmd.Flags().BoolVar(&diffFailOnBreaking, "fail-on-breaking", false, "Exit with an error when a breaking change is found")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	reportFormat := report.Format(strings.ToLower(diffFormat))
+	if !slices.Contains(report.ChangeFormats(), string(reportFormat)) {
+		return fmt.Errorf("invalid format: %s. Valid formats: %v", diffFormat, report.ChangeFormats())
+	}
+
+	from, err := loader.LoadFile(args[0])
+	if err != nil {
+		return err
+	}
+	to, err := loader.LoadFile(args[1])
+	if err != nil {
+		return err
+	}
+
+	changes := diff.Compare(from, to, diff.Options{
+		TypeThreshold:     diffTypeThreshold,
+		PresenceThreshold: diffPresenceThreshold,
+		GrowthThreshold:   diffGrowthThreshold,
+	})
+
+	err = writeOutput(diffOutput, func(w io.Writer) error {
+		return report.WriteChanges(w, "Schema Diff", reportName(args[0], from), reportName(args[1], to), changes, reportFormat)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write diff report: %w", err)
+	}
+
+	if summary := report.SummarizeChanges(changes); diffFailOnBreaking && summary.Breaking > 0 {
+		return fmt.Errorf("found %d breaking changes", summary.Breaking)
+	}
+	return nil
+}
+
+// reportName labels a scan report by path and scan timestamp
+func reportName(path string, result *types.ScanResult) string {
+	if result.ScanTimestamp == "" {
+		return path
+	}
+	return fmt.Sprintf("%s (%s)", path, result.ScanTimestamp)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"mongo-scanner/internal/lint"
+	"mongo-scanner/internal/loader"
+	"mongo-scanner/internal/report"
+	"mongo-scanner/internal/types"
+)
+
+var (
+	lintInput        string
+	lintOutput       string
+	lintFormat       string
+	lintMaxKeyLength int
+	lintFailOn       string
+)
+
+// lintCmd checks field naming conventions in a scan report
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check field naming conventions in a scan report",
+	Long: `Lint analyzes the field names of a previously exported scan report and flags:
+- Inconsistent casing styles within a collection and across the cluster
+- Keys that differ only by case or separator (createdAt vs created_at)
+- Keys containing . or $ or surrounding whitespace
+- Very long keys
+- Reserved-looking names (__proto__, __v, leading underscores)`,
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&lintInput, "input", "", "Scan report to lint (JSON or YAML, required)")
+	lintCmd.Flags().StringVar(&lintOutput, "output", "-", "Output file path (- for stdout)")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format: text, json, markdown, junit, or sarif")
+	lintCmd.Flags().IntVar(&lintMaxKeyLength, "max-key-length", 64, "Key length above which keys are reported")
+	lintCmd.Flags().StringVar(&lintFailOn, "fail-on", "", "Exit with an error when a finding of this severity or higher exists: info, warning, or error")
+
+	lintCmd.MarkFlagRequired("input")
+
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	reportFormat := report.Format(strings.ToLower(lintFormat))
+	if !isValidReportFormat(reportFormat) {
+		return fmt.Errorf("invalid format: %s. Valid formats: %v", lintFormat, report.ValidFormats())
+	}
+	if lintFailOn != "" && !isValidSeverity(lintFailOn) {
+		return fmt.Errorf("invalid --fail-on severity: %s", lintFailOn)
+	}
+
+	result, err := loader.LoadFile(lintInput)
+	if err != nil {
+		return err
+	}
+
+	findings := lint.Lint(result, lint.Options{MaxKeyLength: lintMaxKeyLength})
+
+	err = writeOutput(lintOutput, func(w io.Writer) error {
+		return report.WriteFindings(w, "Naming Lint", findings, reportFormat, types.Severity(lintFailOn))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write lint report: %w", err)
+	}
+
+	return checkFailOn(findings, lintFailOn)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"mongo-scanner/internal/exporter"
+	"mongo-scanner/internal/output"
+)
+
+// exportTarget is an export format and the path it is written to
+type exportTarget struct {
+	Format   exporter.Format
+	Path     string
+	Exporter exporter.Exporter
+}
+
+// resolveTargets combines the --format list and the --output values into
+// export targets. An --output value is either "format=path", which sets
+// the path of one format, or a plain path. A single format is written to
+// the plain path as given; with several formats, each format without its
+// own path is written next to it with the format's extension, such as
+// schema.csv and schema.md for ./schema.json. When only format=path pairs
+// are given and --format is left unset, just those formats are written.
+func resolveTargets(formats string, formatSet bool, outputs []string, opts exporter.Options) ([]exportTarget, error) {
+	var plain string
+	paths := make(map[exporter.Format]string)
+	var paired []exporter.Format
+	for _, out := range outputs {
+		if name, path, ok := strings.Cut(out, "="); ok && isValidExportFormat(exporter.Format(strings.ToLower(name))) {
+			format := exporter.Format(strings.ToLower(name))
+			if _, dup := paths[format]; dup {
+				return nil, fmt.Errorf("duplicate --output for format %s", format)
+			}
+			paths[format] = path
+			paired = append(paired, format)
+			continue
+		}
+
+		if plain != "" {
+			return nil, fmt.Errorf("only one --output path without a format is allowed, got %s and %s", plain, out)
+		}
+		plain = out
+	}
+
+	var listed []exporter.Format
+	if formatSet || len(paired) == 0 {
+		for _, name := range strings.Split(formats, ",") {
+			format := exporter.Format(strings.ToLower(strings.TrimSpace(name)))
+			if !isValidExportFormat(format) {
+				return nil, fmt.Errorf("invalid format: %s. Valid formats: %v", name, exporter.ValidFormats())
+			}
+			if !slices.Contains(listed, format) {
+				listed = append(listed, format)
+			}
+		}
+	}
+
+	if plain == "" {
+		plain = "./schema.json"
+	}
+	unpaired := 0
+	for _, format := range listed {
+		if _, ok := paths[format]; !ok {
+			unpaired++
+		}
+	}
+
+	var targets []exportTarget
+	seen := make(map[string]exporter.Format)
+	add := func(format exporter.Format, path string) error {
+		if opts.Split && exporter.SupportsSplit(format) && !output.IsLocal(path) {
+			return fmt.Errorf("--split writes %s into a local directory, got %s", format, output.Redact(path))
+		}
+		if other, dup := seen[filepath.Clean(path)]; dup {
+			return fmt.Errorf("formats %s and %s are both written to %s", other, format, output.Redact(path))
+		}
+		seen[filepath.Clean(path)] = format
+
+		exp, err := exporter.NewExporterWithOptions(format, opts)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, exportTarget{Format: format, Path: path, Exporter: exp})
+		return nil
+	}
+
+	for _, format := range listed {
+		path, ok := paths[format]
+		switch {
+		case ok:
+		case unpaired == 1 && len(paired) == 0:
+			path = plain
+		case plain == output.Stdout:
+			return nil, fmt.Errorf("only one format can be written to stdout; use format=path outputs for the others")
+		default:
+			path = derivedPath(plain, format, opts.Split)
+		}
+		if err := add(format, path); err != nil {
+			return nil, err
+		}
+	}
+	for _, format := range paired {
+		if !slices.Contains(listed, format) {
+			if err := add(format, paths[format]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// derivedPath replaces the extension of path with the extension of a
+// format, keeping any compression extension, or with "-<format>" for the
+// output directory of a split format
+func derivedPath(path string, format exporter.Format, split bool) string {
+	compression := output.CompressionExt(path)
+	base := strings.TrimSuffix(path, compression)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	if split && exporter.SupportsSplit(format) {
+		return base + "-" + string(format)
+	}
+	return base + exporter.Extension(format) + compression
+}
+
+// isValidExportFormat checks an export format name
+func isValidExportFormat(format exporter.Format) bool {
+	return slices.Contains(exporter.ValidFormats(), string(format))
+}
+
+// parseCSVOptions parses the --csv-columns list and the --csv-delimiter,
+// which is a single character or "tab"
+func parseCSVOptions(columns, delimiter string, long bool) (exporter.CSVOptions, error) {
+	opts := exporter.CSVOptions{Long: long}
+	if columns != "" {
+		for _, name := range strings.Split(columns, ",") {
+			opts.Columns = append(opts.Columns, strings.ToLower(strings.TrimSpace(name)))
+		}
+	}
+
+	switch strings.ToLower(delimiter) {
+	case "tab", `\t`:
+		opts.Delimiter = '\t'
+	default:
+		r, size := utf8.DecodeRuneInString(delimiter)
+		if size == 0 || size != len(delimiter) {
+			return opts, fmt.Errorf("invalid CSV delimiter: %q. Use a single character or tab", delimiter)
+		}
+		opts.Delimiter = r
+	}
+	return opts, nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"mongo-scanner/internal/output"
+	"mongo-scanner/internal/report"
+	"mongo-scanner/internal/types"
+)
+
+// writeOutput writes a report to an output location (a file, a registered
+// remote destination, compressed by extension), or to stdout when path is
+// empty or "-"
+func writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "" {
+		path = output.Stdout
+	}
+
+	w, err := output.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(w); err != nil {
+		w.Abort()
+		return err
+	}
+	return w.Commit()
+}
+
+// checkFailOn returns an error when findings reach the --fail-on severity
+func checkFailOn(findings []types.Finding, failOn string) error {
+	if failOn == "" {
+		return nil
+	}
+
+	threshold := types.Severity(failOn)
+	highest := report.MaxSeverity(findings)
+	if highest != "" && highest.Rank() >= threshold.Rank() {
+		summary := report.Summarize(findings)
+		return fmt.Errorf("found %d errors, %d warnings, %d info (failing on %s)", summary.Errors, summary.Warnings, summary.Info, failOn)
+	}
+
+	return nil
+}
+
+// isValidSeverity checks a --fail-on flag value
+func isValidSeverity(s string) bool {
+	switch types.Severity(s) {
+	case types.SeverityInfo, types.SeverityWarning, types.SeverityError:
+		return true
+	}
+	return false
+}
+
+// isValidReportFormat checks a findings report --format flag value
+func isValidReportFormat(format report.Format) bool {
+	for _, f := range report.ValidFormats() {
+		if string(format) == f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"mongo-scanner/internal/types"
+)
+
+func TestCheckFailOn(t *testing.T) {
+	warning := []types.Finding{{Rule: "new-mixed-type", Severity: types.SeverityWarning}}
+
+	tests := []struct {
+		name     string
+		findings []types.Finding
+		failOn   string
+		fail     bool
+	}{
+		{"no threshold", warning, "", false},
+		{"no findings", nil, "info", false},
+		{"below threshold", warning, "error", false},
+		{"at threshold", warning, "warning", true},
+		{"above threshold", warning, "info", true},
+		{"error at error", []types.Finding{{Severity: types.SeverityInfo}, {Severity: types.SeverityError}}, "error", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFailOn(tt.findings, tt.failOn)
+			if (err != nil) != tt.fail {
+				t.Errorf("checkFailOn(%s) = %v, want failure %v", tt.failOn, err, tt.fail)
+			}
+		})
+	}
+}
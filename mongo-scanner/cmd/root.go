@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -9,21 +10,38 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"mongo-scanner/internal/analyzer"
 	"mongo-scanner/internal/exporter"
 	"mongo-scanner/internal/logger"
+	"mongo-scanner/internal/output"
 	"mongo-scanner/internal/scanner"
 	"mongo-scanner/internal/types"
+	"mongo-scanner/internal/version"
 )
 
 var (
 	// Flags
 	uri      string
-	output   string
+	outputs  []string
 	format   string
 	dbFilter string
 	timeout  int
 	verbose  bool
 	maxDocs  int
+
+	drift       bool
+	driftField  string
+	driftWindow string
+
+	associations bool
+	enums        bool
+
+	requiredThreshold float64
+	split             bool
+
+	csvColumns   string
+	csvDelimiter string
+	csvLong      bool
 )
 
 // rootCmd represents the base command
@@ -47,13 +65,24 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.Version = version.String()
 	rootCmd.Flags().StringVar(&uri, "uri", "", "MongoDB connection URI (required)")
-	rootCmd.Flags().StringVar(&output, "output", "./schema.json", "Output file path")
-	rootCmd.Flags().StringVar(&format, "format", "json", "Output format: json, yaml, or csv")
+	rootCmd.Flags().StringArrayVar(&outputs, "output", []string{"./schema.json"}, "Output file path, or format=path to set the path of one format (repeatable)")
+	rootCmd.Flags().StringVar(&format, "format", "json", "Comma-separated output formats: json, yaml, csv, jsonschema, mongo-validator, postgres, mysql, avro, protobuf, parquet, graphql, openapi, markdown, html, mermaid, plantuml, dot, structurizr, or xlsx")
 	rootCmd.Flags().StringVar(&dbFilter, "db-filter", "", "Comma-separated database name patterns (regex supported)")
 	rootCmd.Flags().IntVar(&timeout, "timeout", 10000, "Scan timeout in seconds")
 	rootCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
 	rootCmd.Flags().IntVar(&maxDocs, "max-docs", 75000, "Maximum documents to sample per collection")
+	rootCmd.Flags().BoolVar(&drift, "drift", false, "Analyze schema drift over time windows")
+	rootCmd.Flags().StringVar(&driftField, "drift-field", "_id", "Field used to bucket documents for drift analysis (_id uses the ObjectId timestamp)")
+	rootCmd.Flags().StringVar(&driftWindow, "drift-window", "month", "Drift window size: day, week, month, quarter, or year")
+	rootCmd.Flags().Float64Var(&requiredThreshold, "required-threshold", 100, "Presence percentage at or above which fields are required in generated schemas")
+	rootCmd.Flags().BoolVar(&split, "split", false, "Write one file per collection into the --output directory (csv, jsonschema, mongo-validator, postgres, mysql, avro, protobuf, parquet, markdown)")
+	rootCmd.Flags().StringVar(&csvColumns, "csv-columns", "", "Comma-separated CSV columns (default: database, collection, document_count, avg_doc_size, path, inferred_type, presence, type_distribution)")
+	rootCmd.Flags().StringVar(&csvDelimiter, "csv-delimiter", ",", "CSV delimiter: a single character, or tab")
+	rootCmd.Flags().BoolVar(&csvLong, "csv-long", false, "Write one CSV row per field and observed type instead of one row per field")
+	rootCmd.Flags().BoolVar(&associations, "associations", false, "Analyze co-occurrence and conditional presence of optional fields")
+	rootCmd.Flags().BoolVar(&enums, "enums", false, "Record the distinct values of low-cardinality string fields as enums")
 
 	rootCmd.MarkFlagRequired("uri")
 }
@@ -61,11 +90,24 @@ func init() {
 func runScan(cmd *cobra.Command, args []string) error {
 	log := logger.NewLogger(verbose)
 
-	// Parse format
-	exportFormat := exporter.Format(strings.ToLower(format))
-	exp, err := exporter.NewExporter(exportFormat)
+	// Resolve formats and output paths before scanning, so that a typo
+	// does not cost a full scan
+	csvOpts, err := parseCSVOptions(csvColumns, csvDelimiter, csvLong)
+	if err != nil {
+		return err
+	}
+	targets, err := resolveTargets(format, cmd.Flags().Changed("format"), outputs, exporter.Options{
+		RequiredThreshold: requiredThreshold,
+		Split:             split,
+		CSV:               csvOpts,
+	})
 	if err != nil {
-		return fmt.Errorf("invalid format: %s. Valid formats: %v", format, exporter.ValidFormats())
+		return err
+	}
+
+	// Validate drift options
+	if drift && !analyzer.IsValidDriftWindow(driftWindow) {
+		return fmt.Errorf("invalid drift window: %s. Valid windows: %v", driftWindow, analyzer.ValidDriftWindows())
 	}
 
 	// Parse database filter
@@ -85,14 +127,25 @@ func runScan(cmd *cobra.Command, args []string) error {
 		DBFilter:    dbFilters,
 		Verbose:     verbose,
 		Concurrency: 5,
+
+		Associations: associations,
+		Enums:        enums,
+	}
+	if drift {
+		opts.DriftField = driftField
+		opts.DriftWindow = driftWindow
 	}
 
 	log.Info("Starting MongoDB schema scan...")
-	log.Debug("URI: %s", maskURI(uri))
-	log.Debug("Output: %s", output)
-	log.Debug("Format: %s", format)
+	log.Debug("URI: %s", scanner.MaskURI(uri))
+	for _, target := range targets {
+		log.Debug("Output: %s (%s)", output.Redact(target.Path), target.Format)
+	}
 	log.Debug("Timeout: %d seconds", timeout)
 	log.Debug("Max docs per collection: %d", maxDocs)
+	if drift {
+		log.Debug("Drift: %s by %s", driftWindow, driftField)
+	}
 
 	// Create scanner
 	s, err := scanner.NewScanner(opts, log)
@@ -115,28 +168,25 @@ func runScan(cmd *cobra.Command, args []string) error {
 	elapsed := time.Since(startTime)
 	log.Info("Scan completed in %s", elapsed.Round(time.Millisecond))
 
-	// Export results
-	if err := exp.ExportToFile(result, output); err != nil {
-		return fmt.Errorf("failed to export results: %w", err)
+	// Export results. Every format is rendered from the same scan, and a
+	// failed format does not prevent the others from being written.
+	var exportErrs []error
+	for _, target := range targets {
+		if err := target.Exporter.ExportToFile(result, target.Path); err != nil {
+			log.Error("Failed to export %s: %v", target.Format, err)
+			exportErrs = append(exportErrs, fmt.Errorf("%s: %w", target.Format, err))
+			continue
+		}
+		log.Info("Schema exported to: %s", output.Redact(target.Path))
 	}
 
-	log.Info("Schema exported to: %s", output)
-
 	// Print summary
 	printSummary(result, log)
 
-	return nil
-}
-
-// maskURI hides the password in the URI for logging
-func maskURI(uri string) string {
-	// Simple masking - replace password portion
-	if idx := strings.Index(uri, "@"); idx > 0 {
-		prefix := uri[:strings.Index(uri, "://")+3]
-		suffix := uri[idx:]
-		return prefix + "****:****" + suffix
+	if len(exportErrs) > 0 {
+		return fmt.Errorf("failed to export results: %w", errors.Join(exportErrs...))
 	}
-	return uri
+	return nil
 }
 
 // printSummary outputs a summary of the scan
@@ -147,7 +197,7 @@ func printSummary(result *types.ScanResult, log *logger.Logger) {
 	for _, db := range result.Databases {
 		totalCollections += len(db.Collections)
 		for _, coll := range db.Collections {
-			totalFields += countFields(coll.Fields)
+			totalFields += types.CountFields(coll.Fields)
 		}
 	}
 
@@ -157,14 +207,3 @@ func printSummary(result *types.ScanResult, log *logger.Logger) {
 	log.Info("Collections: %d", totalCollections)
 	log.Info("Total Fields: %d", totalFields)
 }
-
-// countFields recursively counts fields including nested
-func countFields(fields []types.Field) int {
-	count := len(fields)
-	for _, f := range fields {
-		if len(f.NestedFields) > 0 {
-			count += countFields(f.NestedFields)
-		}
-	}
-	return count
-}
@@ -0,0 +1,232 @@
+package advisor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"mongo-scanner/internal/analyzer"
+	"mongo-scanner/internal/types"
+)
+
+// Advisor rule identifiers
+const (
+	RuleLowPresence        = "low-presence-index"
+	RuleMissingField       = "missing-field-index"
+	RuleRedundantIndex     = "redundant-index"
+	RuleUnindexedReference = "unindexed-reference"
+	RuleTTLNonDate         = "ttl-non-date"
+)
+
+// Options configures the index advisor
+type Options struct {
+	// MinPresence is the field presence percentage below which a
+	// non-sparse, non-partial index is reported
+	MinPresence float64
+}
+
+// DefaultOptions returns default advisor settings
+func DefaultOptions() Options {
+	return Options{
+		MinPresence: 50,
+	}
+}
+
+// Advise cross-references index definitions with observed field statistics
+func Advise(result *types.ScanResult, opts Options) []types.Finding {
+	if opts.MinPresence <= 0 {
+		opts.MinPresence = DefaultOptions().MinPresence
+	}
+
+	var findings []types.Finding
+
+	for _, db := range result.Databases {
+		refs := make(map[string][]types.Reference)
+		for _, ref := range analyzer.InferReferences(db) {
+			refs[ref.Collection] = append(refs[ref.Collection], ref)
+		}
+
+		for _, coll := range db.Collections {
+			a := &collectionAdvisor{db: db.Name, coll: coll, opts: opts}
+			a.checkIndexes()
+			a.checkRedundant()
+			a.checkReferences(refs[coll.Name])
+			findings = append(findings, a.findings...)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		if a.Collection != b.Collection {
+			return a.Collection < b.Collection
+		}
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+		return a.Path < b.Path
+	})
+
+	return findings
+}
+
+// collectionAdvisor accumulates findings for a single collection
+type collectionAdvisor struct {
+	db       string
+	coll     types.Collection
+	opts     Options
+	findings []types.Finding
+}
+
+func (a *collectionAdvisor) add(rule string, severity types.Severity, index types.Index, message, suggestion string) {
+	a.findings = append(a.findings, types.Finding{
+		Rule:       rule,
+		Severity:   severity,
+		Database:   a.db,
+		Collection: a.coll.Name,
+		Index:      index.Name,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
+// checkIndexes reports low-presence, missing and TTL issues per index
+func (a *collectionAdvisor) checkIndexes() {
+	sampled := len(a.coll.Fields) > 0
+
+	for _, index := range a.coll.IndexDefinitions() {
+		if index.Name == "_id_" {
+			continue
+		}
+
+		maxPresence := 0.0
+		var missing []string
+		for _, key := range index.Keys {
+			if !isDataField(key.Field) {
+				maxPresence = 100
+				continue
+			}
+			field := types.FindField(a.coll.Fields, key.Field)
+			if field == nil {
+				missing = append(missing, key.Field)
+				continue
+			}
+			maxPresence = max(maxPresence, field.PresencePercent)
+		}
+
+		if sampled && len(missing) > 0 {
+			a.add(RuleMissingField, types.SeverityWarning, index,
+				fmt.Sprintf("index %s uses fields not present in the sample: %s", index.Name, strings.Join(missing, ", ")),
+				"verify the fields are still written, otherwise drop the index")
+		}
+
+		if sampled && len(missing) < len(index.Keys) && maxPresence < a.opts.MinPresence && !index.Sparse && index.PartialFilter == "" {
+			first := index.Keys[0].Field
+			a.add(RuleLowPresence, types.SeverityWarning, index,
+				fmt.Sprintf("index %s covers fields present in only %.1f%% of documents", index.Name, maxPresence),
+				fmt.Sprintf("make the index sparse or partial, e.g. partialFilterExpression: {%q: {$exists: true}}", first))
+		}
+
+		if index.ExpireAfterSeconds != nil {
+			a.checkTTL(index)
+		}
+	}
+}
+
+// checkTTL reports TTL indexes that cannot expire documents
+func (a *collectionAdvisor) checkTTL(index types.Index) {
+	if len(index.Keys) != 1 {
+		a.add(RuleTTLNonDate, types.SeverityError, index,
+			fmt.Sprintf("TTL index %s is compound; MongoDB ignores expireAfterSeconds on compound indexes", index.Name),
+			"create a single-field TTL index on a date field")
+		return
+	}
+
+	field := types.FindField(a.coll.Fields, index.Keys[0].Field)
+	if field == nil || field.InferredType == "date" {
+		return
+	}
+
+	a.add(RuleTTLNonDate, types.SeverityError, index,
+		fmt.Sprintf("TTL index %s is on %s, which is inferred as %s; documents without a date value never expire", index.Name, index.Keys[0].Field, field.InferredType),
+		"store a BSON date in the indexed field")
+}
+
+// checkRedundant reports indexes whose key pattern is a prefix of another index
+func (a *collectionAdvisor) checkRedundant() {
+	indexes := a.coll.IndexDefinitions()
+
+	for i, index := range indexes {
+		if index.Name == "_id_" || index.Unique || index.Sparse || index.PartialFilter != "" || index.ExpireAfterSeconds != nil {
+			continue
+		}
+
+		for j, other := range indexes {
+			if i == j || other.Sparse || other.PartialFilter != "" || !isKeyPrefix(index.Keys, other.Keys) {
+				continue
+			}
+			// For identical key patterns only report the later index once
+			if len(index.Keys) == len(other.Keys) && i < j {
+				continue
+			}
+
+			a.add(RuleRedundantIndex, types.SeverityWarning, index,
+				fmt.Sprintf("index %s is a prefix of %s", index.Name, other.Name),
+				fmt.Sprintf("drop %s; queries on its keys can use %s", index.Name, other.Name))
+			break
+		}
+	}
+}
+
+// checkReferences reports inferred reference fields without a supporting index
+func (a *collectionAdvisor) checkReferences(refs []types.Reference) {
+	indexes := a.coll.IndexDefinitions()
+
+	for _, ref := range refs {
+		indexed := false
+		for _, index := range indexes {
+			if len(index.Keys) > 0 && index.Keys[0].Field == ref.Path {
+				indexed = true
+				break
+			}
+		}
+		if indexed {
+			continue
+		}
+
+		target := "another collection"
+		if ref.TargetCollection != "" {
+			target = ref.TargetCollection
+		}
+		a.findings = append(a.findings, types.Finding{
+			Rule:       RuleUnindexedReference,
+			Severity:   types.SeverityInfo,
+			Database:   a.db,
+			Collection: a.coll.Name,
+			Path:       ref.Path,
+			Message:    fmt.Sprintf("%s looks like a reference to %s but no index starts with it", ref.Path, target),
+			Suggestion: fmt.Sprintf("create an index {%q: 1} if documents are looked up by this field", ref.Path),
+		})
+	}
+}
+
+// isKeyPrefix reports whether keys is a prefix of (or equal to) other
+func isKeyPrefix(keys, other []types.IndexKey) bool {
+	if len(keys) == 0 || len(keys) > len(other) {
+		return false
+	}
+	for i := range keys {
+		if keys[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isDataField reports whether an index key refers to a document field rather
+// than text index internals or wildcard projections
+func isDataField(field string) bool {
+	return field != "_fts" && field != "_ftsx" && !strings.Contains(field, "$**")
+}
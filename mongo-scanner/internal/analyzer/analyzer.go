@@ -5,12 +5,20 @@ import (
 	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"mongo-scanner/internal/types"
 )
 
+// AnalyzeOptions configures document analysis
+type AnalyzeOptions struct {
+	// Enums records the distinct values of low-cardinality string fields.
+	// The values are copied from sampled documents into the scan result.
+	Enums bool
+}
+
 // AnalyzeDocuments analyzes a slice of documents and returns field statistics
-func AnalyzeDocuments(docs []bson.M) *types.CollectionAnalysis {
+func AnalyzeDocuments(docs []bson.M, opts AnalyzeOptions) *types.CollectionAnalysis {
 	if len(docs) == 0 {
 		return &types.CollectionAnalysis{
 			Fields:           []types.Field{},
@@ -31,12 +39,12 @@ func AnalyzeDocuments(docs []bson.M) *types.CollectionAnalysis {
 	var rareFields []string
 
 	for path, stat := range fieldStats {
-		// Skip nested paths (they'll be handled as nested_fields)
-		if strings.Contains(path, ".") {
+		// Skip nested paths and array items (they'll be handled as nested_fields and array_items)
+		if strings.Contains(path, ".") || strings.Contains(path, "[]") {
 			continue
 		}
 
-		field := buildField(path, stat, fieldStats, totalDocs)
+		field := buildField(path, stat, fieldStats, totalDocs, opts)
 		fields = append(fields, field)
 
 		if field.PresencePercent < 5.0 {
@@ -71,6 +79,51 @@ type fieldStat struct {
 	types       map[string]int
 	isObject    bool
 	isArray     bool
+	nonEmpty    int
+	values      map[string]int
+	manyValues  bool
+}
+
+const (
+	// maxEnumValues is the largest number of distinct strings reported as an enum
+	maxEnumValues = 10
+	// minEnumOccurrences is the number of string values needed before inferring an enum
+	minEnumOccurrences = 20
+)
+
+// recordValue tracks distinct string values until there are too many to form an enum
+func (s *fieldStat) recordValue(value interface{}) {
+	str, ok := value.(string)
+	if !ok || s.manyValues {
+		return
+	}
+	if s.values == nil {
+		s.values = make(map[string]int)
+	}
+	if _, seen := s.values[str]; !seen && len(s.values) >= maxEnumValues {
+		s.manyValues = true
+		s.values = nil
+		return
+	}
+	s.values[str]++
+}
+
+// enumValues returns the sorted distinct values of a low-cardinality string field
+func (s *fieldStat) enumValues(inferredType string) []string {
+	if s.manyValues || inferredType != "string" || s.types["string"] < minEnumOccurrences {
+		return nil
+	}
+
+	// Require every value to repeat so free-form text is not mistaken for an enum
+	values := make([]string, 0, len(s.values))
+	for value, count := range s.values {
+		if count < 2 {
+			return nil
+		}
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
 }
 
 // extractFields recursively extracts all field paths from a document
@@ -94,27 +147,57 @@ func extractFields(doc bson.M, prefix string, stats map[string]*fieldStat) {
 		// Detect type
 		typeName := types.GetBSONTypeName(value)
 		stat.types[typeName]++
+		stat.recordValue(value)
 
 		// Handle nested objects
 		if typeName == "object" {
 			stat.isObject = true
-			if nestedDoc, ok := value.(bson.M); ok {
+			if nestedDoc, ok := asDocument(value); ok {
 				extractFields(nestedDoc, path, stats)
-			} else if nestedDoc, ok := value.(map[string]interface{}); ok {
-				extractFields(bson.M(nestedDoc), path, stats)
 			}
 		}
 
 		// Handle arrays
 		if typeName == "array" {
 			stat.isArray = true
-			if arr, ok := value.([]interface{}); ok {
+			if arr, ok := asArray(value); ok {
 				analyzeArray(arr, path, stats)
 			}
 		}
 	}
 }
 
+// asDocument returns value as a bson.M if it holds any document representation
+func asDocument(value interface{}) (bson.M, bool) {
+	switch v := value.(type) {
+	case bson.M:
+		return v, true
+	case map[string]interface{}:
+		return bson.M(v), true
+	case bson.D:
+		doc := make(bson.M, len(v))
+		for _, elem := range v {
+			doc[elem.Key] = elem.Value
+		}
+		return doc, true
+	default:
+		return nil, false
+	}
+}
+
+// asArray returns value as a slice if it holds any array representation.
+// Documents decoded into bson.M carry arrays as the named type primitive.A.
+func asArray(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case primitive.A:
+		return []interface{}(v), true
+	case []interface{}:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
 // analyzeArray analyzes array contents
 func analyzeArray(arr []interface{}, path string, stats map[string]*fieldStat) {
 	arrayPath := path + "[]"
@@ -125,43 +208,39 @@ func analyzeArray(arr []interface{}, path string, stats map[string]*fieldStat) {
 		}
 	}
 
+	if len(arr) > 0 {
+		stats[path].nonEmpty++
+	}
+
+	itemStat := stats[arrayPath]
 	for _, item := range arr {
 		typeName := types.GetBSONTypeName(item)
-		stats[arrayPath].types[typeName]++
-		stats[arrayPath].occurrences++
+		itemStat.types[typeName]++
+		itemStat.occurrences++
+		itemStat.recordValue(item)
 
 		// If array contains objects, analyze their structure
 		if typeName == "object" {
-			if nestedDoc, ok := item.(bson.M); ok {
+			itemStat.isObject = true
+			if nestedDoc, ok := asDocument(item); ok {
 				extractFields(nestedDoc, arrayPath, stats)
-			} else if nestedDoc, ok := item.(map[string]interface{}); ok {
-				extractFields(bson.M(nestedDoc), arrayPath, stats)
+			}
+		}
+
+		// Arrays of arrays
+		if typeName == "array" {
+			itemStat.isArray = true
+			if nestedArr, ok := asArray(item); ok {
+				analyzeArray(nestedArr, arrayPath, stats)
 			}
 		}
 	}
 }
 
 // buildField creates a Field struct from collected statistics
-func buildField(path string, stat *fieldStat, allStats map[string]*fieldStat, totalDocs int) types.Field {
+func buildField(path string, stat *fieldStat, allStats map[string]*fieldStat, totalDocs int, opts AnalyzeOptions) types.Field {
 	// Calculate type frequencies
-	typeFreqs := make([]types.TypeFrequency, 0, len(stat.types))
-	totalOccurrences := 0
-	for _, count := range stat.types {
-		totalOccurrences += count
-	}
-
-	for typeName, count := range stat.types {
-		freq := float64(count) / float64(totalOccurrences) * 100
-		typeFreqs = append(typeFreqs, types.TypeFrequency{
-			Type:             typeName,
-			FrequencyPercent: round2(freq),
-		})
-	}
-
-	// Sort types by frequency (descending)
-	sort.Slice(typeFreqs, func(i, j int) bool {
-		return typeFreqs[i].FrequencyPercent > typeFreqs[j].FrequencyPercent
-	})
+	typeFreqs := typeFrequencies(stat.types)
 
 	// Infer type
 	inferredType := inferType(typeFreqs)
@@ -175,17 +254,86 @@ func buildField(path string, stat *fieldStat, allStats map[string]*fieldStat, to
 		InferredType:    inferredType,
 		PresencePercent: round2(presencePercent),
 	}
+	if opts.Enums {
+		field.Enum = stat.enumValues(inferredType)
+	}
 
 	// Add nested fields for objects
 	if stat.isObject {
-		field.NestedFields = getNestedFields(path, allStats, totalDocs)
+		field.NestedFields = getNestedFields(path, allStats, totalDocs, opts)
+	}
+
+	// Add item schema for arrays
+	if stat.isArray {
+		field.ArrayItems = buildArrayItems(path, stat, allStats, opts)
 	}
 
 	return field
 }
 
+// buildArrayItems creates the element schema of an array field. Its presence is
+// the share of non-empty arrays, and the presence of its nested fields is
+// relative to the number of object elements.
+func buildArrayItems(path string, stat *fieldStat, allStats map[string]*fieldStat, opts AnalyzeOptions) *types.Field {
+	itemsPath := path + "[]"
+	itemStat, ok := allStats[itemsPath]
+	if !ok {
+		return nil
+	}
+
+	typeFreqs := typeFrequencies(itemStat.types)
+	items := &types.Field{
+		Path:         "[]",
+		Types:        typeFreqs,
+		InferredType: inferType(typeFreqs),
+	}
+	if opts.Enums {
+		items.Enum = itemStat.enumValues(items.InferredType)
+	}
+
+	if arrays := stat.types["array"]; arrays > 0 {
+		items.PresencePercent = round2(float64(stat.nonEmpty) / float64(arrays) * 100)
+	}
+
+	if itemStat.isObject {
+		items.NestedFields = getNestedFields(itemsPath, allStats, itemStat.types["object"], opts)
+	}
+
+	if itemStat.isArray {
+		items.ArrayItems = buildArrayItems(itemsPath, itemStat, allStats, opts)
+	}
+
+	return items
+}
+
+// typeFrequencies converts raw type counts into percentages sorted by frequency (descending)
+func typeFrequencies(counts map[string]int) []types.TypeFrequency {
+	typeFreqs := make([]types.TypeFrequency, 0, len(counts))
+	totalOccurrences := 0
+	for _, count := range counts {
+		totalOccurrences += count
+	}
+
+	for typeName, count := range counts {
+		freq := float64(count) / float64(totalOccurrences) * 100
+		typeFreqs = append(typeFreqs, types.TypeFrequency{
+			Type:             typeName,
+			FrequencyPercent: round2(freq),
+		})
+	}
+
+	sort.Slice(typeFreqs, func(i, j int) bool {
+		if typeFreqs[i].FrequencyPercent != typeFreqs[j].FrequencyPercent {
+			return typeFreqs[i].FrequencyPercent > typeFreqs[j].FrequencyPercent
+		}
+		return typeFreqs[i].Type < typeFreqs[j].Type
+	})
+
+	return typeFreqs
+}
+
 // getNestedFields collects all nested fields under a given path
-func getNestedFields(parentPath string, allStats map[string]*fieldStat, totalDocs int) []types.Field {
+func getNestedFields(parentPath string, allStats map[string]*fieldStat, totalDocs int, opts AnalyzeOptions) []types.Field {
 	var nested []types.Field
 	prefix := parentPath + "."
 
@@ -193,8 +341,8 @@ func getNestedFields(parentPath string, allStats map[string]*fieldStat, totalDoc
 		if strings.HasPrefix(path, prefix) {
 			// Check if this is a direct child (not nested deeper)
 			remaining := strings.TrimPrefix(path, prefix)
-			if !strings.Contains(remaining, ".") {
-				field := buildField(remaining, stat, allStats, totalDocs)
+			if !strings.Contains(remaining, ".") && !strings.Contains(remaining, "[]") {
+				field := buildField(path, stat, allStats, totalDocs, opts)
 				field.Path = remaining // Use relative path
 				nested = append(nested, field)
 			}
@@ -275,7 +423,10 @@ func DetectFieldTypes(values []interface{}) []types.TypeFrequency {
 	}
 
 	sort.Slice(typeFreqs, func(i, j int) bool {
-		return typeFreqs[i].FrequencyPercent > typeFreqs[j].FrequencyPercent
+		if typeFreqs[i].FrequencyPercent != typeFreqs[j].FrequencyPercent {
+			return typeFreqs[i].FrequencyPercent > typeFreqs[j].FrequencyPercent
+		}
+		return typeFreqs[i].Type < typeFreqs[j].Type
 	})
 
 	return typeFreqs
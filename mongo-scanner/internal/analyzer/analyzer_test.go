@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mongo-scanner/internal/types"
+)
+
+// decodeDocuments round-trips documents through BSON so they arrive the way
+// the driver returns them, with arrays as primitive.A
+func decodeDocuments(t *testing.T, docs ...bson.D) []bson.M {
+	t.Helper()
+
+	decoded := make([]bson.M, 0, len(docs))
+	for _, doc := range docs {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		var m bson.M
+		if err := bson.Unmarshal(raw, &m); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		decoded = append(decoded, m)
+	}
+	return decoded
+}
+
+func findField(fields []types.Field, path string) *types.Field {
+	for i := range fields {
+		if fields[i].Path == path {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+func TestAnalyzeDocumentsDecodedArrays(t *testing.T) {
+	docs := decodeDocuments(t,
+		bson.D{
+			{Key: "tags", Value: bson.A{"a", "b"}},
+			{Key: "items", Value: bson.A{
+				bson.D{{Key: "sku", Value: "x1"}, {Key: "qty", Value: int32(2)}},
+				bson.D{{Key: "sku", Value: "x2"}},
+			}},
+		},
+		bson.D{
+			{Key: "tags", Value: bson.A{}},
+			{Key: "items", Value: bson.A{
+				bson.D{{Key: "sku", Value: "x3"}, {Key: "qty", Value: int32(1)}},
+			}},
+		},
+	)
+	if _, ok := docs[0]["items"].(primitive.A); !ok {
+		t.Fatalf("decoded array is %T, want primitive.A", docs[0]["items"])
+	}
+
+	analysis := AnalyzeDocuments(docs, AnalyzeOptions{})
+
+	tags := findField(analysis.Fields, "tags")
+	if tags == nil || tags.ArrayItems == nil {
+		t.Fatalf("tags has no array items: %+v", tags)
+	}
+	if tags.ArrayItems.InferredType != "string" {
+		t.Errorf("tags items type = %s, want string", tags.ArrayItems.InferredType)
+	}
+	if tags.ArrayItems.PresencePercent != 50 {
+		t.Errorf("tags items presence = %v, want 50", tags.ArrayItems.PresencePercent)
+	}
+
+	items := findField(analysis.Fields, "items")
+	if items == nil || items.ArrayItems == nil {
+		t.Fatalf("items has no array items: %+v", items)
+	}
+	if items.ArrayItems.InferredType != "object" {
+		t.Errorf("items element type = %s, want object", items.ArrayItems.InferredType)
+	}
+
+	sku := findField(items.ArrayItems.NestedFields, "sku")
+	qty := findField(items.ArrayItems.NestedFields, "qty")
+	if sku == nil || qty == nil {
+		t.Fatalf("element nested fields = %+v, want sku and qty", items.ArrayItems.NestedFields)
+	}
+	if sku.InferredType != "string" || sku.PresencePercent != 100 {
+		t.Errorf("sku = %s %.2f%%, want string 100%%", sku.InferredType, sku.PresencePercent)
+	}
+	if qty.InferredType != "int32" || qty.PresencePercent != 66.67 {
+		t.Errorf("qty = %s %.2f%%, want int32 66.67%%", qty.InferredType, qty.PresencePercent)
+	}
+}
@@ -0,0 +1,315 @@
+package analyzer
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"mongo-scanner/internal/types"
+)
+
+// Field association kinds
+const (
+	// AssocCoOccur means both fields are (almost) always present together
+	AssocCoOccur = "co_occur"
+	// AssocImplies means whenever Field is present, Related is present too
+	AssocImplies = "implies"
+	// AssocMutuallyExclusive means the fields (almost) never appear together
+	AssocMutuallyExclusive = "mutually_exclusive"
+	// AssocPresentWhen means Field is present exactly when Related equals Value
+	AssocPresentWhen = "present_when"
+)
+
+// AssociationOptions configures field co-occurrence analysis
+type AssociationOptions struct {
+	// MinConfidence is the conditional probability (0-1) required to report an association
+	MinConfidence float64
+	// MinSupport is the minimum number of documents a field or value must appear in
+	MinSupport int
+	// MaxFields caps the number of optional fields compared pairwise
+	MaxFields int
+	// MaxDiscriminatorValues is the maximum cardinality of a field used as a condition
+	MaxDiscriminatorValues int
+}
+
+// DefaultAssociationOptions returns default co-occurrence settings
+func DefaultAssociationOptions() AssociationOptions {
+	return AssociationOptions{
+		MinConfidence:          0.95,
+		MinSupport:             5,
+		MaxFields:              200,
+		MaxDiscriminatorValues: 20,
+	}
+}
+
+// bitset records which sampled documents contain a field or value
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+func (b bitset) count() int {
+	total := 0
+	for _, w := range b {
+		total += bits.OnesCount64(w)
+	}
+	return total
+}
+
+func (b bitset) intersectCount(other bitset) int {
+	total := 0
+	for i := range b {
+		total += bits.OnesCount64(b[i] & other[i])
+	}
+	return total
+}
+
+// presenceSet tracks a field path and the documents it appears in
+type presenceSet struct {
+	path  string
+	docs  bitset
+	count int
+}
+
+// discriminator tracks the documents holding each value of a low-cardinality field
+type discriminator struct {
+	path     string
+	values   map[string]bitset
+	overflow bool
+}
+
+// AnalyzeAssociations computes pairwise co-occurrence and mutual exclusion among
+// optional fields, plus fields whose presence depends on another field's value
+func AnalyzeAssociations(docs []bson.M, opts AssociationOptions) []types.FieldAssociation {
+	defaults := DefaultAssociationOptions()
+	if opts.MinConfidence <= 0 || opts.MinConfidence > 1 {
+		opts.MinConfidence = defaults.MinConfidence
+	}
+	if opts.MinSupport <= 0 {
+		opts.MinSupport = defaults.MinSupport
+	}
+	if opts.MaxFields <= 0 {
+		opts.MaxFields = defaults.MaxFields
+	}
+	if opts.MaxDiscriminatorValues <= 0 {
+		opts.MaxDiscriminatorValues = defaults.MaxDiscriminatorValues
+	}
+
+	totalDocs := len(docs)
+	if totalDocs == 0 {
+		return nil
+	}
+
+	presence := make(map[string]bitset)
+	discriminators := make(map[string]*discriminator)
+
+	for i, doc := range docs {
+		collectPresence(doc, "", func(path string, value interface{}) {
+			set, ok := presence[path]
+			if !ok {
+				set = newBitset(totalDocs)
+				presence[path] = set
+			}
+			set.set(i)
+
+			key, ok := discriminatorKey(value)
+			if !ok {
+				return
+			}
+			d, ok := discriminators[path]
+			if !ok {
+				d = &discriminator{path: path, values: make(map[string]bitset)}
+				discriminators[path] = d
+			}
+			if d.overflow {
+				return
+			}
+			valueSet, ok := d.values[key]
+			if !ok {
+				if len(d.values) >= opts.MaxDiscriminatorValues {
+					d.overflow = true
+					return
+				}
+				valueSet = newBitset(totalDocs)
+				d.values[key] = valueSet
+			}
+			valueSet.set(i)
+		})
+	}
+
+	// Keep optional fields with enough support. Nested fields that are present
+	// whenever their parent is would only repeat the parent's associations.
+	var optional []presenceSet
+	for path, set := range presence {
+		count := set.count()
+		if idx := strings.LastIndex(path, "."); idx > 0 {
+			if parent, ok := presence[path[:idx]]; ok && parent.count() == count {
+				continue
+			}
+		}
+		if count < totalDocs && count >= opts.MinSupport {
+			optional = append(optional, presenceSet{path: path, docs: set, count: count})
+		}
+	}
+	sort.Slice(optional, func(i, j int) bool {
+		if optional[i].count != optional[j].count {
+			return optional[i].count > optional[j].count
+		}
+		return optional[i].path < optional[j].path
+	})
+	if len(optional) > opts.MaxFields {
+		optional = optional[:opts.MaxFields]
+	}
+
+	var associations []types.FieldAssociation
+
+	// Pairwise co-occurrence among optional fields
+	for i := 0; i < len(optional); i++ {
+		for j := i + 1; j < len(optional); j++ {
+			a, b := optional[i], optional[j]
+			if isAncestorPath(a.path, b.path) || isAncestorPath(b.path, a.path) {
+				continue
+			}
+			if assoc, ok := pairAssociation(a, b, opts.MinConfidence); ok {
+				associations = append(associations, assoc)
+			}
+		}
+	}
+
+	// Conditional presence on discriminator values
+	for _, d := range discriminators {
+		if d.overflow || len(d.values) < 2 {
+			continue
+		}
+		for value, valueSet := range d.values {
+			valueCount := valueSet.count()
+			if valueCount < opts.MinSupport {
+				continue
+			}
+			for _, f := range optional {
+				if f.path == d.path || isAncestorPath(f.path, d.path) || isAncestorPath(d.path, f.path) {
+					continue
+				}
+				both := f.docs.intersectCount(valueSet)
+				fieldGivenValue := float64(both) / float64(valueCount)
+				valueGivenField := float64(both) / float64(f.count)
+				if fieldGivenValue >= opts.MinConfidence && valueGivenField >= opts.MinConfidence {
+					associations = append(associations, types.FieldAssociation{
+						Kind:         AssocPresentWhen,
+						Field:        f.path,
+						Related:      d.path,
+						Value:        value,
+						FieldCount:   f.count,
+						RelatedCount: valueCount,
+						BothCount:    both,
+						Confidence:   round2(min(fieldGivenValue, valueGivenField) * 100),
+						Message:      fmt.Sprintf("%s is present when %s == %s", f.path, d.path, value),
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(associations, func(i, j int) bool {
+		if associations[i].Kind != associations[j].Kind {
+			return associations[i].Kind < associations[j].Kind
+		}
+		if associations[i].Field != associations[j].Field {
+			return associations[i].Field < associations[j].Field
+		}
+		if associations[i].Related != associations[j].Related {
+			return associations[i].Related < associations[j].Related
+		}
+		return associations[i].Value < associations[j].Value
+	})
+
+	return associations
+}
+
+// pairAssociation classifies the relationship between two optional fields
+func pairAssociation(a, b presenceSet, minConfidence float64) (types.FieldAssociation, bool) {
+	// Report pairs in path order so output is stable
+	if b.path < a.path {
+		a, b = b, a
+	}
+
+	both := a.docs.intersectCount(b.docs)
+	bGivenA := float64(both) / float64(a.count)
+	aGivenB := float64(both) / float64(b.count)
+
+	assoc := types.FieldAssociation{
+		Field:        a.path,
+		Related:      b.path,
+		FieldCount:   a.count,
+		RelatedCount: b.count,
+		BothCount:    both,
+	}
+
+	switch {
+	case bGivenA >= minConfidence && aGivenB >= minConfidence:
+		assoc.Kind = AssocCoOccur
+		assoc.Confidence = round2(min(bGivenA, aGivenB) * 100)
+		assoc.Message = fmt.Sprintf("%s and %s appear together", a.path, b.path)
+	case bGivenA >= minConfidence:
+		assoc.Kind = AssocImplies
+		assoc.Confidence = round2(bGivenA * 100)
+		assoc.Message = fmt.Sprintf("%s is present whenever %s is present", b.path, a.path)
+	case aGivenB >= minConfidence:
+		assoc.Kind = AssocImplies
+		assoc.Field, assoc.Related = b.path, a.path
+		assoc.FieldCount, assoc.RelatedCount = b.count, a.count
+		assoc.Confidence = round2(aGivenB * 100)
+		assoc.Message = fmt.Sprintf("%s is present whenever %s is present", a.path, b.path)
+	case bGivenA <= 1-minConfidence && aGivenB <= 1-minConfidence:
+		assoc.Kind = AssocMutuallyExclusive
+		assoc.Confidence = round2((1 - max(bGivenA, aGivenB)) * 100)
+		assoc.Message = fmt.Sprintf("%s and %s never appear together", a.path, b.path)
+	default:
+		return types.FieldAssociation{}, false
+	}
+
+	return assoc, true
+}
+
+// collectPresence walks a document and reports every object field path it contains.
+// Array contents are not descended into since their presence is per element.
+func collectPresence(doc bson.M, prefix string, visit func(path string, value interface{})) {
+	for key, value := range doc {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		visit(path, value)
+
+		if nested, ok := asDocument(value); ok {
+			collectPresence(nested, path, visit)
+		}
+	}
+}
+
+// discriminatorKey returns a comparable representation of scalar values
+// that can act as a condition for another field's presence
+func discriminatorKey(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v), true
+	case bool, int, int32, int64:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+// isAncestorPath reports whether parent is an enclosing object path of child
+func isAncestorPath(parent, child string) bool {
+	return strings.HasPrefix(child, parent+".")
+}
@@ -0,0 +1,175 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mongo-scanner/internal/types"
+)
+
+const (
+	// MaxBSONDocumentSize is the MongoDB document size limit (16 MiB)
+	MaxBSONDocumentSize = 16 * 1024 * 1024
+	// NearLimitThreshold is the size from which a document counts as near the limit
+	NearLimitThreshold = MaxBSONDocumentSize * 90 / 100
+	// maxOutliers caps the number of outlier documents reported per collection
+	maxOutliers = 20
+)
+
+// AnalyzeDocumentStats computes size, nesting depth and key count distributions
+// for sampled documents. sizes holds the raw BSON size of each document as read
+// from the server; when it is nil the documents are re-marshalled instead.
+func AnalyzeDocumentStats(docs []bson.M, sizes []int) *types.DocumentStats {
+	stats := &types.DocumentStats{
+		SampleSize:              len(docs),
+		NearLimitThresholdBytes: NearLimitThreshold,
+		Outliers:                []types.DocumentOutlier{},
+	}
+	if len(docs) == 0 {
+		return stats
+	}
+
+	docSizes := make([]int64, len(docs))
+	depths := make([]int64, len(docs))
+	keyCounts := make([]int64, len(docs))
+
+	for i, doc := range docs {
+		if sizes != nil && i < len(sizes) {
+			docSizes[i] = int64(sizes[i])
+		} else {
+			data, _ := bson.Marshal(doc)
+			docSizes[i] = int64(len(data))
+		}
+		depths[i] = int64(documentDepth(doc))
+		keyCounts[i] = int64(len(doc))
+
+		if docSizes[i] >= NearLimitThreshold {
+			stats.NearLimitCount++
+		}
+	}
+
+	stats.SizeBytes = distribution(docSizes)
+	stats.Depth = distribution(depths)
+	stats.KeyCount = distribution(keyCounts)
+
+	// Flag documents above the 99th percentile of any metric
+	for i, doc := range docs {
+		var reasons []string
+		if docSizes[i] >= NearLimitThreshold {
+			reasons = append(reasons, "near_size_limit")
+		} else if docSizes[i] > stats.SizeBytes.P99 {
+			reasons = append(reasons, "size")
+		}
+		if depths[i] > stats.Depth.P99 {
+			reasons = append(reasons, "depth")
+		}
+		if keyCounts[i] > stats.KeyCount.P99 {
+			reasons = append(reasons, "key_count")
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		stats.Outliers = append(stats.Outliers, types.DocumentOutlier{
+			ID:        formatID(doc["_id"]),
+			SizeBytes: docSizes[i],
+			Depth:     int(depths[i]),
+			KeyCount:  int(keyCounts[i]),
+			Reasons:   reasons,
+		})
+	}
+
+	sort.Slice(stats.Outliers, func(i, j int) bool {
+		if stats.Outliers[i].SizeBytes != stats.Outliers[j].SizeBytes {
+			return stats.Outliers[i].SizeBytes > stats.Outliers[j].SizeBytes
+		}
+		return stats.Outliers[i].ID < stats.Outliers[j].ID
+	})
+	if len(stats.Outliers) > maxOutliers {
+		stats.Outliers = stats.Outliers[:maxOutliers]
+	}
+
+	return stats
+}
+
+// distribution computes min, percentiles, max and mean of a sample
+func distribution(values []int64) types.Distribution {
+	if len(values) == 0 {
+		return types.Distribution{}
+	}
+
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	total := int64(0)
+	for _, v := range sorted {
+		total += v
+	}
+
+	return types.Distribution{
+		Min:  sorted[0],
+		P50:  percentile(sorted, 50),
+		P95:  percentile(sorted, 95),
+		P99:  percentile(sorted, 99),
+		Max:  sorted[len(sorted)-1],
+		Mean: round2(float64(total) / float64(len(sorted))),
+	}
+}
+
+// percentile returns the nearest-rank percentile of a sorted sample
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// documentDepth returns the nesting depth of a value, counting both
+// embedded documents and arrays as a level
+func documentDepth(val interface{}) int {
+	deepest := 0
+	switch v := val.(type) {
+	case bson.M:
+		for _, child := range v {
+			deepest = max(deepest, documentDepth(child))
+		}
+	case map[string]interface{}:
+		for _, child := range v {
+			deepest = max(deepest, documentDepth(child))
+		}
+	case bson.D:
+		for _, elem := range v {
+			deepest = max(deepest, documentDepth(elem.Value))
+		}
+	case primitive.A:
+		for _, child := range v {
+			deepest = max(deepest, documentDepth(child))
+		}
+	case []interface{}:
+		for _, child := range v {
+			deepest = max(deepest, documentDepth(child))
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
+
+// formatID renders a document _id for reporting
+func formatID(id interface{}) string {
+	switch v := id.(type) {
+	case nil:
+		return ""
+	case primitive.ObjectID:
+		return v.Hex()
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
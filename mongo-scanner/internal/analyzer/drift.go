@@ -0,0 +1,267 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"mongo-scanner/internal/types"
+)
+
+// Drift window granularities
+const (
+	WindowDay     = "day"
+	WindowWeek    = "week"
+	WindowMonth   = "month"
+	WindowQuarter = "quarter"
+	WindowYear    = "year"
+)
+
+// Drift event kinds
+const (
+	DriftAppeared    = "appeared"
+	DriftDisappeared = "disappeared"
+	DriftTypeChanged = "type_changed"
+)
+
+// ValidDriftWindows returns the list of supported drift window granularities
+func ValidDriftWindows() []string {
+	return []string{WindowDay, WindowWeek, WindowMonth, WindowQuarter, WindowYear}
+}
+
+// DriftOptions configures schema drift analysis
+type DriftOptions struct {
+	// Field is the path used for bucketing. "_id" uses the ObjectId timestamp,
+	// any other path must hold a date.
+	Field string
+	// Window is the bucket granularity (see ValidDriftWindows)
+	Window string
+}
+
+// driftBucket accumulates field statistics for a single time window
+type driftBucket struct {
+	label string
+	start time.Time
+	docs  int
+	stats map[string]*fieldStat
+}
+
+// AnalyzeDrift buckets documents into time windows and reports how each
+// field's presence and type mix changed across them
+func AnalyzeDrift(docs []bson.M, opts DriftOptions) (*types.SchemaDrift, error) {
+	if opts.Field == "" {
+		opts.Field = "_id"
+	}
+	if opts.Window == "" {
+		opts.Window = WindowMonth
+	}
+	if !IsValidDriftWindow(opts.Window) {
+		return nil, fmt.Errorf("unsupported drift window: %s", opts.Window)
+	}
+
+	drift := &types.SchemaDrift{
+		BucketField: opts.Field,
+		Window:      opts.Window,
+		Windows:     []types.DriftWindow{},
+		Fields:      []types.FieldDrift{},
+	}
+
+	buckets := make(map[string]*driftBucket)
+	for _, doc := range docs {
+		ts, ok := bucketTime(lookupPath(doc, opts.Field))
+		if !ok {
+			drift.UnbucketedDocuments++
+			continue
+		}
+
+		label, start := windowFor(ts.UTC(), opts.Window)
+		bucket, exists := buckets[label]
+		if !exists {
+			bucket = &driftBucket{
+				label: label,
+				start: start,
+				stats: make(map[string]*fieldStat),
+			}
+			buckets[label] = bucket
+		}
+
+		bucket.docs++
+		extractFields(doc, "", bucket.stats)
+	}
+
+	// Order windows chronologically
+	ordered := make([]*driftBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		ordered = append(ordered, bucket)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].start.Before(ordered[j].start)
+	})
+
+	paths := make(map[string]bool)
+	for _, bucket := range ordered {
+		drift.Windows = append(drift.Windows, types.DriftWindow{
+			Label:         bucket.label,
+			Start:         bucket.start.Format(time.RFC3339),
+			DocumentCount: bucket.docs,
+		})
+		for path := range bucket.stats {
+			paths[path] = true
+		}
+	}
+
+	sortedPaths := make([]string, 0, len(paths))
+	for path := range paths {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Strings(sortedPaths)
+
+	for _, path := range sortedPaths {
+		fieldDrift := types.FieldDrift{Path: path}
+
+		for _, bucket := range ordered {
+			stat, ok := bucket.stats[path]
+			if !ok {
+				continue
+			}
+
+			if fieldDrift.FirstSeen == "" {
+				fieldDrift.FirstSeen = bucket.label
+			}
+			fieldDrift.LastSeen = bucket.label
+
+			fieldDrift.Windows = append(fieldDrift.Windows, types.FieldWindowStats{
+				Label:           bucket.label,
+				PresencePercent: round2(float64(stat.occurrences) / float64(bucket.docs) * 100),
+				Types:           typeFrequencies(stat.types),
+			})
+		}
+
+		drift.Fields = append(drift.Fields, fieldDrift)
+		drift.Events = append(drift.Events, driftEvents(fieldDrift, drift.Windows)...)
+	}
+
+	sort.SliceStable(drift.Events, func(i, j int) bool {
+		return drift.Events[i].Window < drift.Events[j].Window
+	})
+
+	return drift, nil
+}
+
+// driftEvents derives appearance, disappearance and type change events for a field
+func driftEvents(field types.FieldDrift, windows []types.DriftWindow) []types.DriftEvent {
+	if len(windows) < 2 || len(field.Windows) == 0 {
+		return nil
+	}
+
+	var events []types.DriftEvent
+
+	if field.FirstSeen != windows[0].Label {
+		events = append(events, types.DriftEvent{
+			Window:  field.FirstSeen,
+			Path:    field.Path,
+			Kind:    DriftAppeared,
+			Message: fmt.Sprintf("field %s first appeared in %s", field.Path, field.FirstSeen),
+		})
+	}
+
+	previous := ""
+	for _, w := range field.Windows {
+		current := inferType(w.Types)
+		if previous != "" && current != previous {
+			events = append(events, types.DriftEvent{
+				Window:  w.Label,
+				Path:    field.Path,
+				Kind:    DriftTypeChanged,
+				Message: fmt.Sprintf("field %s changed type from %s to %s in %s", field.Path, previous, current, w.Label),
+			})
+		}
+		previous = current
+	}
+
+	// The field disappeared in the first window after the last one it was
+	// seen in
+	for i, w := range windows[:len(windows)-1] {
+		if w.Label != field.LastSeen {
+			continue
+		}
+		gone := windows[i+1].Label
+		events = append(events, types.DriftEvent{
+			Window:  gone,
+			Path:    field.Path,
+			Kind:    DriftDisappeared,
+			Message: fmt.Sprintf("field %s stopped appearing in %s", field.Path, gone),
+		})
+		break
+	}
+
+	return events
+}
+
+// lookupPath resolves a dotted path inside a document
+func lookupPath(doc bson.M, path string) interface{} {
+	var current interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		nested, ok := asDocument(current)
+		if !ok {
+			return nil
+		}
+		current = nested[key]
+	}
+	return current
+}
+
+// bucketTime extracts a timestamp from an ObjectId or date value
+func bucketTime(val interface{}) (time.Time, bool) {
+	switch v := val.(type) {
+	case primitive.ObjectID:
+		return v.Timestamp(), true
+	case primitive.DateTime:
+		return v.Time(), true
+	case time.Time:
+		return v, true
+	case primitive.Timestamp:
+		return time.Unix(int64(v.T), 0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// windowFor returns the label and start time of the window containing t
+func windowFor(t time.Time, window string) (string, time.Time) {
+	switch window {
+	case WindowDay:
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return start.Format("2006-01-02"), start
+	case WindowWeek:
+		year, week := t.ISOWeek()
+		// Monday of the ISO week
+		offset := (int(t.Weekday()) + 6) % 7
+		start := time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+		return fmt.Sprintf("%d-W%02d", year, week), start
+	case WindowQuarter:
+		quarter := (int(t.Month())-1)/3 + 1
+		start := time.Date(t.Year(), time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return fmt.Sprintf("%d-Q%d", t.Year(), quarter), start
+	case WindowYear:
+		start := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start.Format("2006"), start
+	default:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start.Format("2006-01"), start
+	}
+}
+
+// IsValidDriftWindow checks a window granularity against the supported list
+func IsValidDriftWindow(window string) bool {
+	for _, w := range ValidDriftWindows() {
+		if w == window {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"strings"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/types"
+)
+
+// InferReferences finds fields that look like references to documents in
+// other collections of the same database, such as customerId or order_ids.
+// ObjectId-typed fields are always reported; fields of other types only
+// when a matching target collection exists.
+func InferReferences(db types.Database) []types.Reference {
+	collections := make(map[string]string, len(db.Collections))
+	for _, coll := range db.Collections {
+		collections[naming.Normalize(coll.Name)] = coll.Name
+	}
+
+	var refs []types.Reference
+	for _, coll := range db.Collections {
+		walkFields(coll.Fields, "", func(path string, field types.Field) {
+			base, many, ok := referenceBase(field.Path)
+			if !ok {
+				return
+			}
+
+			target := resolveCollection(base, collections)
+			switch {
+			case field.InferredType == "objectId":
+			case field.InferredType == "array" && many:
+			case target != "":
+			default:
+				return
+			}
+
+			refs = append(refs, types.Reference{
+				Database:         db.Name,
+				Collection:       coll.Name,
+				Path:             path,
+				TargetCollection: target,
+				Many:             field.InferredType == "array",
+			})
+		})
+	}
+
+	return refs
+}
+
+// referenceBase extracts the referenced entity name from keys ending in id or ids
+func referenceBase(key string) (string, bool, bool) {
+	words := naming.Words(key)
+	if len(words) < 2 {
+		return "", false, false
+	}
+
+	switch words[len(words)-1] {
+	case "id":
+		return strings.Join(words[:len(words)-1], ""), false, true
+	case "ids":
+		return strings.Join(words[:len(words)-1], ""), true, true
+	default:
+		return "", false, false
+	}
+}
+
+// resolveCollection matches an entity name to a collection, allowing plurals
+func resolveCollection(base string, collections map[string]string) string {
+	candidates := []string{base, base + "s", base + "es"}
+	if strings.HasSuffix(base, "y") {
+		candidates = append(candidates, strings.TrimSuffix(base, "y")+"ies")
+	}
+
+	for _, candidate := range candidates {
+		if name, ok := collections[candidate]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// walkFields visits every field of a tree with its full dotted path,
+// including the fields of array elements
+func walkFields(fields []types.Field, prefix string, visit func(path string, field types.Field)) {
+	for _, f := range fields {
+		path := f.Path
+		if prefix != "" {
+			path = prefix + "." + f.Path
+		}
+		visit(path, f)
+		if len(f.NestedFields) > 0 {
+			walkFields(f.NestedFields, path, visit)
+		}
+		for items := f.ArrayItems; items != nil; items = items.ArrayItems {
+			walkFields(items.NestedFields, path, visit)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// Target represents a code generation target language
+type Target string
+
+const (
+	TargetGo         Target = "go"
+	TargetTypeScript Target = "ts"
+	TargetMongoose   Target = "mongoose"
+	TargetPydantic   Target = "pydantic"
+)
+
+// File is a generated source file, relative to the output directory
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Options configures code generation
+type Options struct {
+	// RequiredThreshold is the presence percentage at or above which
+	// fields are generated as required
+	RequiredThreshold float64
+
+	// Zod adds Zod schemas to TypeScript output
+	Zod bool
+	// DriverTypes uses mongodb driver classes in TypeScript output
+	DriverTypes bool
+}
+
+// DefaultOptions returns the default code generation options
+func DefaultOptions() Options {
+	return Options{RequiredThreshold: 100}
+}
+
+// Generator generates source files from a scan result
+type Generator interface {
+	Generate(result *types.ScanResult) ([]File, error)
+}
+
+// NewGenerator creates a generator for the specified target
+func NewGenerator(target Target, opts Options) (Generator, error) {
+	switch target {
+	case TargetGo:
+		return &GoGenerator{RequiredThreshold: opts.RequiredThreshold}, nil
+	case TargetTypeScript:
+		return &TypeScriptGenerator{
+			RequiredThreshold: opts.RequiredThreshold,
+			Zod:               opts.Zod,
+			DriverTypes:       opts.DriverTypes,
+		}, nil
+	case TargetMongoose:
+		return &MongooseGenerator{RequiredThreshold: opts.RequiredThreshold}, nil
+	case TargetPydantic:
+		return &PydanticGenerator{RequiredThreshold: opts.RequiredThreshold}, nil
+	default:
+		return nil, fmt.Errorf("unsupported target: %s", target)
+	}
+}
+
+// ValidTargets returns all valid code generation targets
+func ValidTargets() []string {
+	return []string{
+		string(TargetGo),
+		string(TargetTypeScript),
+		string(TargetMongoose),
+		string(TargetPydantic),
+	}
+}
+
+// WriteFiles writes generated files below dir, creating directories as needed
+func WriteFiles(dir string, files []File) error {
+	for _, f := range files {
+		path := filepath.Join(dir, f.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, f.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// presenceScope names what the presence of the fields of object is relative
+// to: documents for top-level fields, the objects holding them otherwise
+func presenceScope(object *types.Field) string {
+	if object == nil {
+		return "documents"
+	}
+	return object.Path + " objects"
+}
+
+// moduleName returns the unique kebab-case file name, without extension, of
+// the JavaScript or TypeScript module of a database, or "models" when the
+// database name has no words
+func moduleName(dbName string, taken map[string]bool) string {
+	name := strings.Join(naming.Words(dbName), "-")
+	if name == "" {
+		name = "models"
+	}
+	return schema.UniqueName(name, taken)
+}
+
+// claimCollectionNames sorts the collections of a database by name and
+// reserves a type name for each, so nested types never take a collection's
+// name and collisions resolve the same way on every run
+func claimCollectionNames(db types.Database, taken map[string]bool, typeName func(collName string) string) ([]types.Collection, []string) {
+	collections := slices.Clone(db.Collections)
+	sort.Slice(collections, func(i, j int) bool { return collections[i].Name < collections[j].Name })
+
+	names := make([]string, len(collections))
+	for i, coll := range collections {
+		names[i] = schema.UniqueName(typeName(coll.Name), taken)
+	}
+	return collections, names
+}
+
+// secondaryIndexes returns the index definitions of a collection without
+// the default _id index
+func secondaryIndexes(coll types.Collection) []types.Index {
+	var indexes []types.Index
+	for _, index := range coll.IndexDefinitions() {
+		if index.Name != "_id_" {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// partialFilter decodes an index's partial filter expression, stored as
+// Extended JSON, into plain JSON values
+func partialFilter(index types.Index) (interface{}, bool) {
+	if index.PartialFilter == "" {
+		return nil, false
+	}
+
+	var doc bson.M
+	if err := bson.UnmarshalExtJSON([]byte(index.PartialFilter), false, &doc); err != nil {
+		return nil, false
+	}
+	relaxed, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return nil, false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(relaxed, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,104 @@
+package codegen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mongo-scanner/internal/types"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// frequencies builds a type distribution from type and percentage pairs
+func frequencies(pairs ...any) []types.TypeFrequency {
+	var out []types.TypeFrequency
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, types.TypeFrequency{Type: pairs[i].(string), FrequencyPercent: pairs[i+1].(float64)})
+	}
+	return out
+}
+
+// goldenResult covers nested objects, arrays of objects and scalars,
+// optional, nullable and mixed fields, enums and keys that are not
+// identifiers in every target
+func goldenResult() *types.ScanResult {
+	return &types.ScanResult{
+		ClusterName: "test",
+		Databases: []types.Database{{
+			Name: "shop",
+			Collections: []types.Collection{
+				{
+					Name: "orders",
+					Fields: []types.Field{
+						{Path: "_id", InferredType: "objectId", PresencePercent: 100, Types: frequencies("objectId", 100.0)},
+						{Path: "status", InferredType: "string", PresencePercent: 100, Types: frequencies("string", 100.0), Enum: []string{"paid", "shipped"}},
+						{Path: "total", InferredType: "decimal", PresencePercent: 100, Types: frequencies("decimal", 100.0)},
+						{Path: "note", InferredType: "string", PresencePercent: 40, Types: frequencies("string", 70.0, "null", 30.0)},
+						{Path: "ref", InferredType: "mixed", PresencePercent: 100, Types: frequencies("string", 60.0, "int32", 40.0)},
+						{Path: "created-at", InferredType: "date", PresencePercent: 100, Types: frequencies("date", 100.0)},
+						{Path: "shipping", InferredType: "object", PresencePercent: 50, Types: frequencies("object", 100.0), NestedFields: []types.Field{
+							{Path: "city", InferredType: "string", PresencePercent: 50, Types: frequencies("string", 100.0)},
+							{Path: "zip", InferredType: "string", PresencePercent: 20, Types: frequencies("string", 100.0)},
+						}},
+						{Path: "items", InferredType: "array", PresencePercent: 100, Types: frequencies("array", 100.0),
+							ArrayItems: &types.Field{Path: "[]", InferredType: "object", PresencePercent: 100, Types: frequencies("object", 100.0), NestedFields: []types.Field{
+								{Path: "sku", InferredType: "string", PresencePercent: 100, Types: frequencies("string", 100.0)},
+								{Path: "qty", InferredType: "int32", PresencePercent: 90, Types: frequencies("int32", 100.0)},
+							}}},
+						{Path: "tags", InferredType: "array", PresencePercent: 100, Types: frequencies("array", 100.0),
+							ArrayItems: &types.Field{Path: "[]", InferredType: "string", PresencePercent: 100, Types: frequencies("string", 100.0)}},
+					},
+				},
+				{
+					Name: "users",
+					Fields: []types.Field{
+						{Path: "_id", InferredType: "objectId", PresencePercent: 100, Types: frequencies("objectId", 100.0)},
+						{Path: "email", InferredType: "string", PresencePercent: 100, Types: frequencies("string", 100.0)},
+						{Path: "age", InferredType: "int64", PresencePercent: 80, Types: frequencies("int64", 100.0)},
+						{Path: "active", InferredType: "boolean", PresencePercent: 100, Types: frequencies("boolean", 100.0)},
+					},
+				},
+			},
+		}},
+	}
+}
+
+func TestGenerateGolden(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Zod = true
+
+	for _, target := range []Target{TargetGo, TargetTypeScript, TargetMongoose, TargetPydantic} {
+		t.Run(string(target), func(t *testing.T) {
+			g, err := NewGenerator(target, opts)
+			if err != nil {
+				t.Fatalf("NewGenerator: %v", err)
+			}
+			files, err := g.Generate(goldenResult())
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if len(files) != 1 {
+				t.Fatalf("generated %d files, want 1", len(files))
+			}
+
+			golden := filepath.Join("testdata", string(target), files[0].Path)
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, files[0].Content, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file (run go test -update to create it): %v", err)
+			}
+			if string(files[0].Content) != string(want) {
+				t.Errorf("%s differs from %s:\n%s", files[0].Path, golden, files[0].Content)
+			}
+		})
+	}
+}
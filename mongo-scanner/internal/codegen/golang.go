@@ -0,0 +1,312 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// goInitialisms are words written in upper case in Go identifiers
+var goInitialisms = map[string]bool{
+	"api": true, "css": true, "dns": true, "html": true, "http": true,
+	"https": true, "id": true, "ids": true, "ip": true, "json": true,
+	"sku": true, "sql": true, "ssh": true, "tcp": true, "ttl": true,
+	"ui": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// GoGenerator generates one Go package per database with one struct per
+// collection, using the BSON types of the mongo driver
+type GoGenerator struct {
+	RequiredThreshold float64
+}
+
+// goFile collects the structs and imports of one generated package
+type goFile struct {
+	pkg     string
+	structs []goStruct
+	names   map[string]bool
+	imports map[string]bool
+}
+
+// goStruct is a generated struct type. skipped lists the keys that cannot
+// be written in a struct tag.
+type goStruct struct {
+	name    string
+	doc     string
+	fields  []goField
+	skipped []string
+}
+
+// goField is a field of a generated struct
+type goField struct {
+	name    string
+	typ     string
+	tag     string
+	comment string
+}
+
+// Generate builds one models.go file per database
+func (g *GoGenerator) Generate(result *types.ScanResult) ([]File, error) {
+	var files []File
+	packages := make(map[string]bool)
+
+	for _, db := range result.Databases {
+		f := &goFile{
+			pkg:     schema.UniqueName(goPackageName(db.Name), packages),
+			names:   make(map[string]bool),
+			imports: make(map[string]bool),
+		}
+		collections, rootNames := claimCollectionNames(db, f.names, func(collName string) string {
+			return goIdentifier(naming.Singular(collName))
+		})
+
+		for i, coll := range collections {
+			doc := "is a document of the " + coll.Name + " collection"
+			g.addStruct(f, rootNames[i], doc, coll.Fields, nil)
+		}
+
+		src, err := format.Source(f.render(result.ClusterName, db.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to format Go code for %s: %w", db.Name, err)
+		}
+		files = append(files, File{Path: f.pkg + "/models.go", Content: src})
+	}
+
+	return files, nil
+}
+
+// addStruct adds a struct for the fields of object, or for the top-level
+// fields when object is nil, under a reserved name. The doc comment is the
+// name followed by doc. Nested objects become further structs named after
+// their parent.
+func (g *GoGenerator) addStruct(f *goFile, name, doc string, fields []types.Field, object *types.Field) {
+	index := len(f.structs)
+	f.structs = append(f.structs, goStruct{name: name, doc: name + " " + doc})
+
+	fieldNames := make(map[string]bool)
+	var out []goField
+	var skipped []string
+	for _, field := range fields {
+		if !isTagKey(field.Path) {
+			skipped = append(skipped, field.Path)
+			continue
+		}
+		typ, comment := g.fieldType(f, name, field)
+
+		optional := !schema.Required(field, object, g.RequiredThreshold) || schema.Nullable(field)
+		if optional && isValueType(typ) {
+			typ = "*" + typ
+		}
+
+		tagOptions := ""
+		if optional {
+			tagOptions = ",omitempty"
+		}
+
+		if presence := schema.Presence(field, object); presence < 100 {
+			note := fmt.Sprintf("present in %.1f%% of %s", presence, presenceScope(object))
+			if comment != "" {
+				comment += "; " + note
+			} else {
+				comment = note
+			}
+		}
+
+		out = append(out, goField{
+			name:    schema.UniqueName(goIdentifier(field.Path), fieldNames),
+			typ:     typ,
+			tag:     fmt.Sprintf(`bson:"%s%s" json:"%s%s"`, field.Path, tagOptions, field.Path, tagOptions),
+			comment: comment,
+		})
+	}
+
+	f.structs[index].fields = out
+	f.structs[index].skipped = skipped
+}
+
+// fieldType maps a field onto a Go type and an optional comment
+func (g *GoGenerator) fieldType(f *goFile, parent string, field types.Field) (string, string) {
+	bsonTypes := schema.Types(field)
+	switch len(bsonTypes) {
+	case 0:
+		return "interface{}", ""
+	case 1:
+		return g.goType(f, parent, field, bsonTypes[0]), ""
+	default:
+		return unionType(bsonTypes), "mixed: " + strings.Join(bsonTypes, ", ")
+	}
+}
+
+// goType maps a single BSON type onto a Go type
+func (g *GoGenerator) goType(f *goFile, parent string, field types.Field, bsonType string) string {
+	switch bsonType {
+	case "string":
+		return "string"
+	case "int32", "int":
+		return "int32"
+	case "int64", "long":
+		return "int64"
+	case "double":
+		return "float64"
+	case "boolean", "bool":
+		return "bool"
+	case "date":
+		f.imports["time"] = true
+		return "time.Time"
+	case "objectId":
+		f.imports["primitive"] = true
+		return "primitive.ObjectID"
+	case "decimal":
+		f.imports["primitive"] = true
+		return "primitive.Decimal128"
+	case "binData":
+		f.imports["primitive"] = true
+		return "primitive.Binary"
+	case "regex":
+		f.imports["primitive"] = true
+		return "primitive.Regex"
+	case "timestamp":
+		f.imports["primitive"] = true
+		return "primitive.Timestamp"
+	case "object":
+		if len(field.NestedFields) == 0 {
+			f.imports["bson"] = true
+			return "bson.M"
+		}
+		name := schema.UniqueName(parent+goIdentifier(field.Path), f.names)
+		g.addStruct(f, name, "is the "+field.Path+" object of "+parent, field.NestedFields, &field)
+		return name
+	case "array":
+		if field.ArrayItems == nil {
+			return "[]interface{}"
+		}
+		items := schema.Elements(field, naming.Singular(field.Path))
+		itemType, _ := g.fieldType(f, parent, items)
+		return "[]" + itemType
+	default:
+		return "interface{}"
+	}
+}
+
+// unionType picks a Go type for a mixed field. Purely numeric mixes widen to
+// int64 or float64; any other mix is left to the caller as interface{}.
+func unionType(bsonTypes []string) string {
+	integer := true
+	for _, t := range bsonTypes {
+		switch t {
+		case "int32", "int64", "int", "long":
+		case "double":
+			integer = false
+		default:
+			return "interface{}"
+		}
+	}
+	if integer {
+		return "int64"
+	}
+	return "float64"
+}
+
+// isTagKey reports whether a key can be written in a bson and json struct
+// tag: commas would start tag options, and quotes, backticks, backslashes
+// and control characters would break the tag literal
+func isTagKey(key string) bool {
+	return !strings.ContainsFunc(key, func(r rune) bool {
+		return r == ',' || r == '"' || r == '`' || r == '\\' || unicode.IsControl(r)
+	})
+}
+
+// isValueType reports whether a Go type needs a pointer to represent absence
+func isValueType(typ string) bool {
+	return !strings.HasPrefix(typ, "[]") && typ != "interface{}" && typ != "bson.M"
+}
+
+// render writes the package source
+func (f *goFile) render(clusterName, dbName string) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by mongo-scanner codegen from %s. DO NOT EDIT.\n\n", clusterName)
+	fmt.Fprintf(&b, "// Package %s contains the document models of the %s database.\n", f.pkg, dbName)
+	fmt.Fprintf(&b, "package %s\n\n", f.pkg)
+
+	if len(f.imports) > 0 {
+		b.WriteString("import (\n")
+		if f.imports["time"] {
+			b.WriteString("\t\"time\"\n\n")
+		}
+		if f.imports["bson"] {
+			b.WriteString("\t\"go.mongodb.org/mongo-driver/bson\"\n")
+		}
+		if f.imports["primitive"] {
+			b.WriteString("\t\"go.mongodb.org/mongo-driver/bson/primitive\"\n")
+		}
+		b.WriteString(")\n\n")
+	}
+
+	for _, s := range f.structs {
+		fmt.Fprintf(&b, "// %s\n", s.doc)
+		fmt.Fprintf(&b, "type %s struct {\n", s.name)
+		for _, field := range s.fields {
+			fmt.Fprintf(&b, "\t%s %s `%s`", field.name, field.typ, field.tag)
+			if field.comment != "" {
+				fmt.Fprintf(&b, " // %s", field.comment)
+			}
+			b.WriteString("\n")
+		}
+		for _, key := range s.skipped {
+			fmt.Fprintf(&b, "\t// %q is not generated: the key cannot be written in a struct tag\n", key)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	return b.Bytes()
+}
+
+// goIdentifier converts a key into an exported Go identifier, writing
+// common initialisms in upper case (userId becomes UserID)
+func goIdentifier(key string) string {
+	var b strings.Builder
+	for _, word := range naming.Words(key) {
+		if goInitialisms[word] {
+			if word == "ids" {
+				b.WriteString("IDs")
+			} else {
+				b.WriteString(strings.ToUpper(word))
+			}
+			continue
+		}
+		b.WriteString(naming.Pascal(word))
+	}
+
+	name := b.String()
+	switch {
+	case name == "":
+		return "Field"
+	case unicode.IsDigit([]rune(name)[0]):
+		return "F" + name
+	default:
+		return name
+	}
+}
+
+// goPackageName converts a database name into a Go package name
+func goPackageName(dbName string) string {
+	name := strings.Join(naming.Words(dbName), "")
+	switch {
+	case name == "":
+		return "models"
+	case unicode.IsDigit([]rune(name)[0]):
+		return "db" + name
+	case token.IsKeyword(name):
+		return name + "models"
+	default:
+		return name
+	}
+}
@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"mongo-scanner/internal/types"
+)
+
+// generateGo generates the Go models of a single database
+func generateGo(t *testing.T, db types.Database) string {
+	t.Helper()
+
+	files, err := (&GoGenerator{RequiredThreshold: 100}).Generate(&types.ScanResult{ClusterName: "test", Databases: []types.Database{db}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("generated %d files, want 1", len(files))
+	}
+	return string(files[0].Content)
+}
+
+func TestGoCollectionNamesWinOverNestedStructs(t *testing.T) {
+	orders := types.Collection{Name: "orders", Fields: []types.Field{
+		{Path: "user", InferredType: "object", PresencePercent: 100, NestedFields: []types.Field{
+			{Path: "name", InferredType: "string", PresencePercent: 100},
+		}},
+	}}
+	orderUsers := types.Collection{Name: "order_users", Fields: []types.Field{
+		{Path: "role", InferredType: "string", PresencePercent: 100},
+	}}
+
+	for _, collections := range [][]types.Collection{{orders, orderUsers}, {orderUsers, orders}} {
+		src := generateGo(t, types.Database{Name: "shop", Collections: collections})
+
+		if !strings.Contains(src, "// OrderUser is a document of the order_users collection") {
+			t.Errorf("order_users collection lost its struct name:\n%s", src)
+		}
+		if !strings.Contains(src, "User OrderUser2 `bson:\"user\" json:\"user\"`") {
+			t.Errorf("nested user struct is not OrderUser2:\n%s", src)
+		}
+	}
+}
+
+func TestGoSkipsKeysThatCannotBeTagged(t *testing.T) {
+	keys := []string{"a,omitempty", `say "hi"`, "back`tick", `back\slash`, "new\nline"}
+	fields := []types.Field{{Path: "name", InferredType: "string", PresencePercent: 100}}
+	for _, key := range keys {
+		fields = append(fields, types.Field{Path: key, InferredType: "string", PresencePercent: 100})
+	}
+
+	src := generateGo(t, types.Database{Name: "shop", Collections: []types.Collection{{Name: "orders", Fields: fields}}})
+
+	if !strings.Contains(src, "Name string `bson:\"name\" json:\"name\"`") {
+		t.Errorf("taggable field missing:\n%s", src)
+	}
+	for _, key := range keys {
+		if want := fmt.Sprintf("// %q is not generated", key); !strings.Contains(src, want) {
+			t.Errorf("no skip note for %q:\n%s", key, src)
+		}
+	}
+	if strings.Count(src, "`bson:") != 1 {
+		t.Errorf("untaggable keys were generated:\n%s", src)
+	}
+}
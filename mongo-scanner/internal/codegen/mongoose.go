@@ -0,0 +1,247 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// MongooseGenerator generates one Mongoose module per database with a schema
+// and model per collection, including the collection's indexes
+type MongooseGenerator struct {
+	RequiredThreshold float64
+}
+
+// mongooseFile collects the schemas of one generated module
+type mongooseFile struct {
+	schemas []mongooseSchema
+	names   map[string]bool
+}
+
+// mongooseSchema is a generated schema. Collection schemas carry the
+// collection name and indexes; nested schemas are embedded without _id.
+type mongooseSchema struct {
+	name       string
+	collection string
+	paths      []mongoosePath
+	indexes    []types.Index
+}
+
+// mongoosePath is a path of a generated schema with its definition
+type mongoosePath struct {
+	key        string
+	definition string
+}
+
+// Generate builds one <db>.js module per database
+func (g *MongooseGenerator) Generate(result *types.ScanResult) ([]File, error) {
+	var files []File
+	fileNames := make(map[string]bool)
+
+	for _, db := range result.Databases {
+		f := &mongooseFile{names: make(map[string]bool)}
+
+		collections, rootNames := claimCollectionNames(db, f.names, func(collName string) string {
+			return pascalIdentifier(naming.Singular(collName))
+		})
+		for i, coll := range collections {
+			index := g.addSchema(f, rootNames[i], coll.Fields, nil)
+			f.schemas[index].collection = coll.Name
+			f.schemas[index].indexes = secondaryIndexes(coll)
+		}
+
+		files = append(files, File{Path: moduleName(db.Name, fileNames) + ".js", Content: g.render(f, result.ClusterName, db.Name)})
+	}
+
+	return files, nil
+}
+
+// addSchema appends the schema of a collection's documents (object nil) or
+// of an embedded object and returns its index in f.schemas, so Generate can
+// attach the collection name and indexes. name must already be reserved.
+func (g *MongooseGenerator) addSchema(f *mongooseFile, name string, fields []types.Field, object *types.Field) int {
+	index := len(f.schemas)
+	f.schemas = append(f.schemas, mongooseSchema{name: name})
+
+	var paths []mongoosePath
+	for _, field := range fields {
+		// Mongoose adds ObjectId _id and the __v version key itself
+		if (field.Path == "_id" && field.InferredType == "objectId") || field.Path == "__v" {
+			continue
+		}
+
+		options := []string{"type: " + g.fieldType(f, name, field)}
+		// required rejects null, so nullable fields are never required
+		if schema.Required(field, object, g.RequiredThreshold) && !schema.Nullable(field) {
+			options = append(options, "required: true")
+		}
+		if len(field.Enum) > 0 {
+			enum := make([]string, len(field.Enum))
+			for i, v := range field.Enum {
+				enum[i] = quote(v)
+			}
+			options = append(options, "enum: ["+strings.Join(enum, ", ")+"]")
+		}
+
+		paths = append(paths, mongoosePath{
+			key:        field.Path,
+			definition: "{ " + strings.Join(options, ", ") + " }",
+		})
+	}
+
+	f.schemas[index].paths = paths
+	return index
+}
+
+// fieldType maps a field onto a Mongoose schema type. Fields whose observed
+// types map onto different schema types use Mixed. Embedded schemas are only
+// added once the field resolves to a single object or array type, so a field
+// mixing objects with other types leaves no unused schema behind.
+func (g *MongooseGenerator) fieldType(f *mongooseFile, parent string, field types.Field) string {
+	var schemaTypes []string
+	for _, bsonType := range schema.Types(field) {
+		typ := schemaType(bsonType)
+		if !slices.Contains(schemaTypes, typ) {
+			schemaTypes = append(schemaTypes, typ)
+		}
+	}
+	if len(schemaTypes) != 1 {
+		return "Schema.Types.Mixed"
+	}
+
+	switch schemaTypes[0] {
+	case "object":
+		if len(field.NestedFields) == 0 {
+			return "Schema.Types.Mixed"
+		}
+		name := schema.UniqueName(parent+pascalIdentifier(field.Path), f.names)
+		g.addSchema(f, name, field.NestedFields, &field)
+		return schemaVariable(name)
+	case "array":
+		if field.ArrayItems == nil {
+			return "[Schema.Types.Mixed]"
+		}
+		items := schema.Elements(field, naming.Singular(field.Path))
+		return "[" + g.fieldType(f, parent, items) + "]"
+	default:
+		return schemaTypes[0]
+	}
+}
+
+// schemaType maps a single BSON type onto a Mongoose schema type. Objects and
+// arrays stay "object" and "array" for fieldType to resolve.
+func schemaType(bsonType string) string {
+	switch bsonType {
+	case "string":
+		return "String"
+	case "int32", "int64", "int", "long", "double":
+		return "Number"
+	case "decimal":
+		return "Schema.Types.Decimal128"
+	case "boolean", "bool":
+		return "Boolean"
+	case "objectId":
+		return "Schema.Types.ObjectId"
+	case "date":
+		return "Date"
+	case "binData":
+		return "Buffer"
+	case "object", "array":
+		return bsonType
+	default:
+		return "Schema.Types.Mixed"
+	}
+}
+
+// render writes the module source. A schema constant has to exist before a
+// parent schema refers to it, so the schemas are written from the last added,
+// the most deeply embedded, back to the collection schemas.
+func (g *MongooseGenerator) render(f *mongooseFile, clusterName, dbName string) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by mongo-scanner codegen from %s. DO NOT EDIT.\n", clusterName)
+	fmt.Fprintf(&b, "// Mongoose models of the %s database.\n\n", dbName)
+	b.WriteString("import mongoose from \"mongoose\";\n\n")
+	b.WriteString("const { Schema } = mongoose;\n")
+
+	for i := len(f.schemas) - 1; i >= 0; i-- {
+		s := f.schemas[i]
+		variable := schemaVariable(s.name)
+
+		b.WriteString("\n")
+		if s.collection == "" {
+			fmt.Fprintf(&b, "const %s = new Schema(\n", variable)
+		} else {
+			fmt.Fprintf(&b, "export const %s = new Schema(\n", variable)
+		}
+		b.WriteString("  {\n")
+		for _, p := range s.paths {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsPropertyName(p.key), p.definition)
+		}
+		b.WriteString("  },\n")
+		if s.collection == "" {
+			b.WriteString("  { _id: false },\n")
+		} else {
+			fmt.Fprintf(&b, "  { collection: %s },\n", quote(s.collection))
+		}
+		b.WriteString(");\n")
+
+		if s.collection == "" {
+			continue
+		}
+
+		if len(s.indexes) > 0 {
+			b.WriteString("\n")
+		}
+		for _, index := range s.indexes {
+			fmt.Fprintf(&b, "%s.index(%s, %s);\n", variable, mongooseIndexKeys(index), mongooseIndexOptions(index))
+		}
+
+		fmt.Fprintf(&b, "\nexport const %s = mongoose.model(%s, %s);\n", s.name, quote(s.name), variable)
+	}
+
+	return b.Bytes()
+}
+
+// mongooseIndexKeys renders an index key pattern as an object literal
+func mongooseIndexKeys(index types.Index) string {
+	keys := make([]string, len(index.Keys))
+	for i, key := range index.Keys {
+		order := key.Order
+		if order != "1" && order != "-1" {
+			order = quote(order)
+		}
+		keys[i] = jsPropertyName(key.Field) + ": " + order
+	}
+	return "{ " + strings.Join(keys, ", ") + " }"
+}
+
+// mongooseIndexOptions renders the options of an index as an object literal
+func mongooseIndexOptions(index types.Index) string {
+	options := []string{"name: " + quote(index.Name)}
+	if index.Unique {
+		options = append(options, "unique: true")
+	}
+	if index.Sparse {
+		options = append(options, "sparse: true")
+	}
+	if index.ExpireAfterSeconds != nil {
+		options = append(options, fmt.Sprintf("expireAfterSeconds: %d", *index.ExpireAfterSeconds))
+	}
+	if filter, ok := partialFilter(index); ok {
+		out, _ := json.Marshal(filter)
+		options = append(options, "partialFilterExpression: "+string(out))
+	}
+	return "{ " + strings.Join(options, ", ") + " }"
+}
+
+// schemaVariable returns the variable name of a schema, such as orderSchema
+func schemaVariable(name string) string {
+	return strings.ToLower(name[:1]) + name[1:] + "Schema"
+}
@@ -0,0 +1,37 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"mongo-scanner/internal/types"
+)
+
+func TestMongooseMixedObjectAddsNoSchema(t *testing.T) {
+	orders := types.Collection{Name: "orders", Fields: []types.Field{
+		{Path: "customer", InferredType: "mixed", PresencePercent: 100,
+			Types: []types.TypeFrequency{{Type: "object", FrequencyPercent: 60}, {Type: "string", FrequencyPercent: 40}},
+			NestedFields: []types.Field{
+				{Path: "name", InferredType: "string", PresencePercent: 100},
+			}},
+		{Path: "shipping", InferredType: "object", PresencePercent: 100, NestedFields: []types.Field{
+			{Path: "city", InferredType: "string", PresencePercent: 100},
+		}},
+	}}
+
+	files, err := (&MongooseGenerator{RequiredThreshold: 100}).Generate(&types.ScanResult{ClusterName: "test", Databases: []types.Database{{Name: "shop", Collections: []types.Collection{orders}}}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	src := string(files[0].Content)
+
+	if !strings.Contains(src, "customer: { type: Schema.Types.Mixed") {
+		t.Errorf("customer is not Mixed:\n%s", src)
+	}
+	if strings.Contains(src, "orderCustomerSchema") {
+		t.Errorf("mixed customer left a schema behind:\n%s", src)
+	}
+	if !strings.Contains(src, "orderShippingSchema") {
+		t.Errorf("shipping schema missing:\n%s", src)
+	}
+}
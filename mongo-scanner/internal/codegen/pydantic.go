@@ -0,0 +1,386 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// pythonKeywords are reserved words that cannot be used as field names
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true,
+	"assert": true, "async": true, "await": true, "break": true, "class": true,
+	"continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true,
+	"if": true, "import": true, "in": true, "is": true, "lambda": true,
+	"nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// pymongoIndexTypes maps index key orders onto pymongo constants
+var pymongoIndexTypes = map[string]string{
+	"1":        "ASCENDING",
+	"-1":       "DESCENDING",
+	"text":     "TEXT",
+	"hashed":   "HASHED",
+	"2d":       "GEO2D",
+	"2dsphere": "GEOSPHERE",
+}
+
+// PydanticGenerator generates one Pydantic v2 module per database with a
+// model per collection. Collection models list their indexes as pymongo
+// IndexModel objects.
+type PydanticGenerator struct {
+	RequiredThreshold float64
+}
+
+// pyFile collects the models and imports of one generated module
+type pyFile struct {
+	models  []pyModel
+	names   map[string]bool
+	imports map[string]map[string]bool
+}
+
+// pyModel is a generated model class
+type pyModel struct {
+	name       string
+	doc        string
+	collection string
+	fields     []pyField
+	indexes    []types.Index
+}
+
+// pyField is a field of a generated model
+type pyField struct {
+	name     string
+	key      string
+	typ      string
+	required bool
+	nullable bool
+}
+
+// Generate builds one <db>.py module per database
+func (g *PydanticGenerator) Generate(result *types.ScanResult) ([]File, error) {
+	var files []File
+	moduleNames := make(map[string]bool)
+
+	for _, db := range result.Databases {
+		f := &pyFile{names: make(map[string]bool), imports: make(map[string]map[string]bool)}
+		f.use("pydantic", "BaseModel", "ConfigDict", "Field")
+
+		collections, rootNames := claimCollectionNames(db, f.names, func(collName string) string {
+			return pascalIdentifier(naming.Singular(collName))
+		})
+		for i, coll := range collections {
+			index := g.addModel(f, rootNames[i], "A document of the "+coll.Name+" collection.", coll.Fields, nil)
+			f.models[index].collection = coll.Name
+			f.models[index].indexes = secondaryIndexes(coll)
+
+			f.use("typing", "ClassVar")
+			if len(f.models[index].indexes) > 0 {
+				f.use("pymongo", "IndexModel")
+			}
+		}
+
+		files = append(files, File{
+			Path:    schema.UniqueName(pyIdentifier(db.Name), moduleNames) + ".py",
+			Content: g.render(f, result.ClusterName, db.Name),
+		})
+	}
+
+	return files, nil
+}
+
+// addModel appends the model of a collection (object nil) or of an embedded
+// object and returns its index in f.models for Generate to complete.
+func (g *PydanticGenerator) addModel(f *pyFile, name, doc string, fields []types.Field, object *types.Field) int {
+	index := len(f.models)
+	f.models = append(f.models, pyModel{name: name, doc: doc})
+
+	// Reserve the class variables of collection models
+	fieldNames := map[string]bool{"model_config": true, "collection_name": true, "indexes": true}
+	var out []pyField
+	for _, field := range fields {
+		out = append(out, pyField{
+			name:     schema.UniqueName(pyIdentifier(field.Path), fieldNames),
+			key:      field.Path,
+			typ:      g.fieldType(f, name, field),
+			required: schema.Required(field, object, g.RequiredThreshold),
+			nullable: schema.Nullable(field),
+		})
+	}
+
+	f.models[index].fields = out
+	return index
+}
+
+// fieldType maps a field onto a Python type hint, as a Union of every
+// observed type or a Literal of the detected enum values
+func (g *PydanticGenerator) fieldType(f *pyFile, parent string, field types.Field) string {
+	if len(field.Enum) > 0 {
+		f.use("typing", "Literal")
+		enum := make([]string, len(field.Enum))
+		for i, v := range field.Enum {
+			enum[i] = quote(v)
+		}
+		return "Literal[" + strings.Join(enum, ", ") + "]"
+	}
+
+	var typeNames []string
+	for _, bsonType := range schema.Types(field) {
+		typ := g.pyType(f, parent, field, bsonType)
+		if !slices.Contains(typeNames, typ) {
+			typeNames = append(typeNames, typ)
+		}
+	}
+
+	switch len(typeNames) {
+	case 0:
+		f.use("typing", "Any")
+		return "Any"
+	case 1:
+		return typeNames[0]
+	default:
+		f.use("typing", "Union")
+		return "Union[" + strings.Join(typeNames, ", ") + "]"
+	}
+}
+
+// pyType maps a single BSON type onto a Python type hint
+func (g *PydanticGenerator) pyType(f *pyFile, parent string, field types.Field, bsonType string) string {
+	switch bsonType {
+	case "string":
+		return "str"
+	case "int32", "int64", "int", "long":
+		return "int"
+	case "double":
+		return "float"
+	case "decimal":
+		f.use("decimal", "Decimal")
+		return "Decimal"
+	case "boolean", "bool":
+		return "bool"
+	case "date":
+		f.use("datetime", "datetime")
+		return "datetime"
+	case "objectId":
+		f.use("beanie", "PydanticObjectId")
+		return "PydanticObjectId"
+	case "binData":
+		return "bytes"
+	case "object":
+		if len(field.NestedFields) == 0 {
+			f.use("typing", "Any")
+			return "dict[str, Any]"
+		}
+		name := schema.UniqueName(parent+pascalIdentifier(field.Path), f.names)
+		g.addModel(f, name, "The "+field.Path+" object of "+parent+".", field.NestedFields, &field)
+		return name
+	case "array":
+		if field.ArrayItems == nil {
+			f.use("typing", "Any")
+			return "list[Any]"
+		}
+		items := schema.Elements(field, naming.Singular(field.Path))
+		return "list[" + g.fieldType(f, parent, items) + "]"
+	default:
+		f.use("typing", "Any")
+		return "Any"
+	}
+}
+
+// use records a "from module import name" import
+func (f *pyFile) use(module string, names ...string) {
+	if f.imports[module] == nil {
+		f.imports[module] = make(map[string]bool)
+	}
+	for _, name := range names {
+		f.imports[module][name] = true
+	}
+}
+
+// render writes the module source. Field annotations are evaluated when a
+// class is created, so models are written innermost first.
+func (g *PydanticGenerator) render(f *pyFile, clusterName, dbName string) []byte {
+	var b bytes.Buffer
+
+	// Render the models first, as they record the imports they use
+	var models bytes.Buffer
+	for i := len(f.models) - 1; i >= 0; i-- {
+		g.renderModel(&models, f, f.models[i])
+	}
+
+	fmt.Fprintf(&b, "# Code generated by mongo-scanner codegen from %s. DO NOT EDIT.\n", clusterName)
+	fmt.Fprintf(&b, "\"\"\"Pydantic models of the %s database.\"\"\"\n\n", dbName)
+
+	// Standard library imports first, then third-party packages
+	modules := make([]string, 0, len(f.imports))
+	for module := range f.imports {
+		modules = append(modules, module)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		iStd, jStd := isPythonStdlib(modules[i]), isPythonStdlib(modules[j])
+		if iStd != jStd {
+			return iStd
+		}
+		return modules[i] < modules[j]
+	})
+	for i, module := range modules {
+		if i > 0 && isPythonStdlib(modules[i-1]) && !isPythonStdlib(module) {
+			b.WriteString("\n")
+		}
+		names := make([]string, 0, len(f.imports[module]))
+		for name := range f.imports[module] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(&b, "from %s import %s\n", module, strings.Join(names, ", "))
+	}
+
+	b.Write(models.Bytes())
+	return b.Bytes()
+}
+
+// renderModel writes a model class
+func (g *PydanticGenerator) renderModel(b *bytes.Buffer, f *pyFile, m pyModel) {
+	fmt.Fprintf(b, "\n\nclass %s(BaseModel):\n", m.name)
+	fmt.Fprintf(b, "    \"\"\"%s\"\"\"\n\n", m.doc)
+	b.WriteString("    model_config = ConfigDict(populate_by_name=True)\n")
+
+	if m.collection != "" {
+		b.WriteString("\n")
+		fmt.Fprintf(b, "    collection_name: ClassVar[str] = %s\n", quote(m.collection))
+		if len(m.indexes) > 0 {
+			b.WriteString("    indexes: ClassVar[list[IndexModel]] = [\n")
+			for _, index := range m.indexes {
+				fmt.Fprintf(b, "        %s,\n", pyIndexModel(f, index))
+			}
+			b.WriteString("    ]\n")
+		}
+	}
+
+	if len(m.fields) > 0 {
+		b.WriteString("\n")
+	}
+	for _, field := range m.fields {
+		typ := field.typ
+		if field.nullable || !field.required {
+			f.use("typing", "Optional")
+			typ = "Optional[" + typ + "]"
+		}
+
+		var args []string
+		if !field.required {
+			args = append(args, "default=None")
+		}
+		if field.name != field.key {
+			args = append(args, "alias="+quote(field.key))
+		}
+
+		switch {
+		case field.name != field.key:
+			fmt.Fprintf(b, "    %s: %s = Field(%s)\n", field.name, typ, strings.Join(args, ", "))
+		case !field.required:
+			fmt.Fprintf(b, "    %s: %s = None\n", field.name, typ)
+		default:
+			fmt.Fprintf(b, "    %s: %s\n", field.name, typ)
+		}
+	}
+}
+
+// pyIndexModel renders an index as a pymongo IndexModel expression
+func pyIndexModel(f *pyFile, index types.Index) string {
+	keys := make([]string, len(index.Keys))
+	for i, key := range index.Keys {
+		order := quote(key.Order)
+		if constant, ok := pymongoIndexTypes[key.Order]; ok {
+			f.use("pymongo", constant)
+			order = constant
+		}
+		keys[i] = "(" + quote(key.Field) + ", " + order + ")"
+	}
+
+	args := []string{"[" + strings.Join(keys, ", ") + "]", "name=" + quote(index.Name)}
+	if index.Unique {
+		args = append(args, "unique=True")
+	}
+	if index.Sparse {
+		args = append(args, "sparse=True")
+	}
+	if index.ExpireAfterSeconds != nil {
+		args = append(args, fmt.Sprintf("expireAfterSeconds=%d", *index.ExpireAfterSeconds))
+	}
+	if filter, ok := partialFilter(index); ok {
+		args = append(args, "partialFilterExpression="+pyLiteral(filter))
+	}
+	return "IndexModel(" + strings.Join(args, ", ") + ")"
+}
+
+// pyLiteral renders a decoded JSON value as a Python literal
+func pyLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if val {
+			return "True"
+		}
+		return "False"
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case string:
+		return quote(val)
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = pyLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = quote(k) + ": " + pyLiteral(val[k])
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return "None"
+	}
+}
+
+// pyIdentifier converts a key into a snake_case Python identifier
+func pyIdentifier(key string) string {
+	name := naming.Snake(key)
+	switch {
+	case name == "":
+		return "field"
+	case unicode.IsDigit([]rune(name)[0]):
+		return "f_" + name
+	case pythonKeywords[name]:
+		return name + "_"
+	default:
+		return name
+	}
+}
+
+// isPythonStdlib reports whether an imported module is part of the standard library
+func isPythonStdlib(module string) bool {
+	switch module {
+	case "datetime", "decimal", "typing":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,43 @@
+// Code generated by mongo-scanner codegen from test. DO NOT EDIT.
+
+// Package shop contains the document models of the shop database.
+package shop
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Order is a document of the orders collection
+type Order struct {
+	ID        primitive.ObjectID   `bson:"_id" json:"_id"`
+	Status    string               `bson:"status" json:"status"`
+	Total     primitive.Decimal128 `bson:"total" json:"total"`
+	Note      *string              `bson:"note,omitempty" json:"note,omitempty"` // present in 40.0% of documents
+	Ref       interface{}          `bson:"ref" json:"ref"`                       // mixed: string, int32
+	CreatedAt time.Time            `bson:"created-at" json:"created-at"`
+	Shipping  *OrderShipping       `bson:"shipping,omitempty" json:"shipping,omitempty"` // present in 50.0% of documents
+	Items     []OrderItem          `bson:"items" json:"items"`
+	Tags      []string             `bson:"tags" json:"tags"`
+}
+
+// OrderShipping is the shipping object of Order
+type OrderShipping struct {
+	City string  `bson:"city" json:"city"`
+	Zip  *string `bson:"zip,omitempty" json:"zip,omitempty"` // present in 40.0% of shipping objects
+}
+
+// OrderItem is the item object of Order
+type OrderItem struct {
+	SKU string `bson:"sku" json:"sku"`
+	Qty *int32 `bson:"qty,omitempty" json:"qty,omitempty"` // present in 90.0% of item objects
+}
+
+// User is a document of the users collection
+type User struct {
+	ID     primitive.ObjectID `bson:"_id" json:"_id"`
+	Email  string             `bson:"email" json:"email"`
+	Age    *int64             `bson:"age,omitempty" json:"age,omitempty"` // present in 80.0% of documents
+	Active bool               `bson:"active" json:"active"`
+}
@@ -0,0 +1,288 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// jsIdentifierPattern matches JavaScript property names that need no quoting
+var jsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// TypeScriptGenerator generates one TypeScript module per database with an
+// interface per collection and, optionally, matching Zod schemas
+type TypeScriptGenerator struct {
+	RequiredThreshold float64
+	// Zod adds a Zod schema next to each interface
+	Zod bool
+	// DriverTypes uses the ObjectId, Decimal128 and Binary classes of the
+	// mongodb package and Date instead of their JSON string forms
+	DriverTypes bool
+}
+
+// tsFile collects the interfaces and imports of one generated module
+type tsFile struct {
+	interfaces []tsInterface
+	names      map[string]bool
+	imports    map[string]bool
+}
+
+// tsInterface is a generated interface
+type tsInterface struct {
+	name  string
+	doc   string
+	props []tsProperty
+}
+
+// tsProperty is a property of a generated interface, with its TypeScript
+// type and Zod schema expression
+type tsProperty struct {
+	key      string
+	optional bool
+	typ      string
+	zod      string
+	comment  string
+}
+
+// Generate builds one <db>.ts module per database
+func (g *TypeScriptGenerator) Generate(result *types.ScanResult) ([]File, error) {
+	var files []File
+	fileNames := make(map[string]bool)
+
+	for _, db := range result.Databases {
+		f := &tsFile{names: make(map[string]bool), imports: make(map[string]bool)}
+
+		collections, rootNames := claimCollectionNames(db, f.names, func(collName string) string {
+			return pascalIdentifier(naming.Singular(collName))
+		})
+
+		for i, coll := range collections {
+			doc := "A document of the " + coll.Name + " collection"
+			g.addInterface(f, rootNames[i], doc, coll.Fields, nil)
+		}
+
+		files = append(files, File{Path: moduleName(db.Name, fileNames) + ".ts", Content: g.render(f, result.ClusterName, db.Name)})
+	}
+
+	return files, nil
+}
+
+// addInterface appends an interface and its Zod schema for a collection
+// (object nil) or an embedded object. Embedded objects of its fields are
+// appended after it by fieldType.
+func (g *TypeScriptGenerator) addInterface(f *tsFile, name, doc string, fields []types.Field, object *types.Field) {
+	index := len(f.interfaces)
+	f.interfaces = append(f.interfaces, tsInterface{name: name, doc: doc})
+
+	var props []tsProperty
+	for _, field := range fields {
+		typ, zod := g.fieldType(f, name, field)
+
+		comment := ""
+		if presence := schema.Presence(field, object); presence < 100 {
+			comment = fmt.Sprintf("Present in %.1f%% of %s", presence, presenceScope(object))
+		}
+
+		props = append(props, tsProperty{
+			key:      field.Path,
+			optional: !schema.Required(field, object, g.RequiredThreshold),
+			typ:      typ,
+			zod:      zod,
+			comment:  comment,
+		})
+	}
+
+	f.interfaces[index].props = props
+}
+
+// fieldType maps a field onto a TypeScript type and a Zod schema, as a union
+// of every observed type, adding null when null values were observed
+func (g *TypeScriptGenerator) fieldType(f *tsFile, parent string, field types.Field) (string, string) {
+	typ, zod := "unknown", "z.unknown()"
+	if len(field.Enum) > 0 {
+		enum := make([]string, len(field.Enum))
+		for i, v := range field.Enum {
+			enum[i] = quote(v)
+		}
+		typ = strings.Join(enum, " | ")
+		zod = "z.enum([" + strings.Join(enum, ", ") + "])"
+	} else {
+		var typeNames, zodSchemas []string
+		for _, bsonType := range schema.Types(field) {
+			t, z := g.tsType(f, parent, field, bsonType)
+			i := slices.Index(typeNames, t)
+			switch {
+			case i < 0:
+				typeNames = append(typeNames, t)
+				zodSchemas = append(zodSchemas, z)
+			case zodSchemas[i] != z && t == "number":
+				// Integers mixed with doubles accept any number
+				zodSchemas[i] = "z.number()"
+			}
+		}
+
+		switch len(typeNames) {
+		case 0:
+		case 1:
+			typ, zod = typeNames[0], zodSchemas[0]
+		default:
+			typ = strings.Join(typeNames, " | ")
+			zod = "z.union([" + strings.Join(zodSchemas, ", ") + "])"
+		}
+	}
+
+	if schema.Nullable(field) && typ != "unknown" {
+		typ += " | null"
+		zod += ".nullable()"
+	}
+	return typ, zod
+}
+
+// tsType maps a single BSON type onto a TypeScript type and a Zod schema
+func (g *TypeScriptGenerator) tsType(f *tsFile, parent string, field types.Field, bsonType string) (string, string) {
+	switch bsonType {
+	case "string", "regex":
+		return "string", "z.string()"
+	case "int32", "int64", "int", "long", "timestamp":
+		return "number", "z.number().int()"
+	case "double":
+		return "number", "z.number()"
+	case "boolean", "bool":
+		return "boolean", "z.boolean()"
+	case "objectId":
+		if g.DriverTypes {
+			f.imports["ObjectId"] = true
+			return "ObjectId", "z.instanceof(ObjectId)"
+		}
+		return "string", "z.string().regex(/^[0-9a-fA-F]{24}$/)"
+	case "date":
+		if g.DriverTypes {
+			return "Date", "z.date()"
+		}
+		return "string", "z.string().datetime()"
+	case "decimal":
+		if g.DriverTypes {
+			f.imports["Decimal128"] = true
+			return "Decimal128", "z.instanceof(Decimal128)"
+		}
+		return "string", "z.string()"
+	case "binData":
+		if g.DriverTypes {
+			f.imports["Binary"] = true
+			return "Binary", "z.instanceof(Binary)"
+		}
+		return "string", "z.string()"
+	case "object":
+		if len(field.NestedFields) == 0 {
+			return "Record<string, unknown>", "z.record(z.unknown())"
+		}
+		name := schema.UniqueName(parent+pascalIdentifier(field.Path), f.names)
+		g.addInterface(f, name, "The "+field.Path+" object of "+parent, field.NestedFields, &field)
+		return name, name + "Schema"
+	case "array":
+		if field.ArrayItems == nil {
+			return "unknown[]", "z.array(z.unknown())"
+		}
+		items := schema.Elements(field, naming.Singular(field.Path))
+		typ, zod := g.fieldType(f, parent, items)
+		if strings.Contains(typ, " ") {
+			typ = "(" + typ + ")"
+		}
+		return typ + "[]", "z.array(" + zod + ")"
+	default:
+		return "unknown", "z.unknown()"
+	}
+}
+
+// render writes the module source. Interfaces may refer to each other in any
+// order, but a Zod schema constant must be declared before its use, so the
+// schemas follow the interfaces from the innermost object outwards.
+func (g *TypeScriptGenerator) render(f *tsFile, clusterName, dbName string) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by mongo-scanner codegen from %s. DO NOT EDIT.\n", clusterName)
+	fmt.Fprintf(&b, "// Models of the %s database.\n\n", dbName)
+
+	if g.Zod {
+		b.WriteString("import { z } from \"zod\";\n")
+	}
+	if len(f.imports) > 0 {
+		var classes []string
+		for class := range f.imports {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		fmt.Fprintf(&b, "import { %s } from \"mongodb\";\n", strings.Join(classes, ", "))
+	}
+	if g.Zod || len(f.imports) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, iface := range f.interfaces {
+		fmt.Fprintf(&b, "/** %s */\n", iface.doc)
+		fmt.Fprintf(&b, "export interface %s {\n", iface.name)
+		for _, p := range iface.props {
+			if p.comment != "" {
+				fmt.Fprintf(&b, "  /** %s */\n", p.comment)
+			}
+			optional := ""
+			if p.optional {
+				optional = "?"
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", jsPropertyName(p.key), optional, p.typ)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	if g.Zod {
+		for i := len(f.interfaces) - 1; i >= 0; i-- {
+			iface := f.interfaces[i]
+			fmt.Fprintf(&b, "export const %sSchema = z.object({\n", iface.name)
+			for _, p := range iface.props {
+				zod := p.zod
+				if p.optional {
+					zod += ".optional()"
+				}
+				fmt.Fprintf(&b, "  %s: %s,\n", jsPropertyName(p.key), zod)
+			}
+			b.WriteString("});\n\n")
+		}
+	}
+
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
+
+// pascalIdentifier converts a key into a PascalCase type name
+func pascalIdentifier(key string) string {
+	name := naming.Pascal(key)
+	switch {
+	case name == "":
+		return "Field"
+	case name[0] >= '0' && name[0] <= '9':
+		return "T" + name
+	default:
+		return name
+	}
+}
+
+// jsPropertyName quotes JavaScript property names that are not valid identifiers
+func jsPropertyName(key string) string {
+	if jsIdentifierPattern.MatchString(key) {
+		return key
+	}
+	return quote(key)
+}
+
+// quote returns a double-quoted string literal
+func quote(s string) string {
+	out, _ := json.Marshal(s)
+	return string(out)
+}
@@ -0,0 +1,405 @@
+package diff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// Change kinds
+const (
+	KindDatabase         = "database"
+	KindCollection       = "collection"
+	KindField            = "field"
+	KindIndex            = "index"
+	KindInferredType     = "inferred-type"
+	KindTypeDistribution = "type-distribution"
+	KindPresence         = "presence"
+	KindDocumentCount    = "document-count"
+	KindDocumentSize     = "document-size"
+	KindDatabaseSize     = "database-size"
+)
+
+// Options configures which changes are reported
+type Options struct {
+	// TypeThreshold is the shift of a type's frequency, in percentage
+	// points, at or above which the type distribution is reported
+	TypeThreshold float64
+	// PresenceThreshold is the change of a field's presence, in percentage
+	// points, at or above which it is reported
+	PresenceThreshold float64
+	// GrowthThreshold is the relative change of document counts and sizes,
+	// in percent, at or above which it is reported
+	GrowthThreshold float64
+}
+
+// DefaultOptions returns default diff settings
+func DefaultOptions() Options {
+	return Options{
+		TypeThreshold:     5,
+		PresenceThreshold: 5,
+		GrowthThreshold:   10,
+	}
+}
+
+// Compare reports the changes from one scan result to another. Databases
+// and collections are compared by name, indexes by name and fields by path.
+// The fields and indexes of added or removed collections are not listed.
+func Compare(from, to *types.ScanResult, opts Options) []types.SchemaChange {
+	var changes []types.SchemaChange
+
+	fromDBs := databasesByName(from)
+	toDBs := databasesByName(to)
+	for _, name := range unionNames(fromDBs, toDBs) {
+		oldDB, inOld := fromDBs[name]
+		newDB, inNew := toDBs[name]
+		switch {
+		case !inOld:
+			changes = append(changes, types.SchemaChange{
+				Kind: KindDatabase, Change: types.ChangeAdded, Database: name,
+				Message: fmt.Sprintf("database added with %d collections", len(newDB.Collections)),
+			})
+		case !inNew:
+			changes = append(changes, types.SchemaChange{
+				Kind: KindDatabase, Change: types.ChangeRemoved, Database: name, Breaking: true,
+				Message: "database removed",
+			})
+		default:
+			changes = append(changes, compareDatabases(oldDB, newDB, opts)...)
+		}
+	}
+
+	return changes
+}
+
+// compareDatabases reports the changes within a database present in both
+// scan results
+func compareDatabases(from, to types.Database, opts Options) []types.SchemaChange {
+	var changes []types.SchemaChange
+
+	// Reports rebuilt from CSV have no database size
+	if from.SizeBytes > 0 && to.SizeBytes > 0 {
+		if change, ok := growth(from.SizeBytes, to.SizeBytes, opts); ok {
+			changes = append(changes, types.SchemaChange{
+				Kind: KindDatabaseSize, Change: types.ChangeModified, Database: to.Name,
+				From: formatBytes(from.SizeBytes), To: formatBytes(to.SizeBytes),
+				Message: fmt.Sprintf("data size %s → %s (%s)", formatBytes(from.SizeBytes), formatBytes(to.SizeBytes), change),
+			})
+		}
+	}
+
+	fromColls := collectionsByName(from)
+	toColls := collectionsByName(to)
+	for _, name := range unionNames(fromColls, toColls) {
+		oldColl, inOld := fromColls[name]
+		newColl, inNew := toColls[name]
+		switch {
+		case !inOld:
+			changes = append(changes, types.SchemaChange{
+				Kind: KindCollection, Change: types.ChangeAdded, Database: to.Name, Collection: name,
+				Message: fmt.Sprintf("collection added with %d documents and %d fields", newColl.DocumentCount, types.CountFields(newColl.Fields)),
+			})
+		case !inNew:
+			changes = append(changes, types.SchemaChange{
+				Kind: KindCollection, Change: types.ChangeRemoved, Database: to.Name, Collection: name, Breaking: true,
+				Message: "collection removed",
+			})
+		default:
+			changes = append(changes, compareCollections(to.Name, oldColl, newColl, opts)...)
+		}
+	}
+
+	return changes
+}
+
+// compareCollections reports the changes within a collection present in
+// both scan results
+func compareCollections(dbName string, from, to types.Collection, opts Options) []types.SchemaChange {
+	var changes []types.SchemaChange
+	base := types.SchemaChange{Database: dbName, Collection: to.Name}
+
+	if change, ok := growth(from.DocumentCount, to.DocumentCount, opts); ok {
+		c := base
+		c.Kind, c.Change = KindDocumentCount, types.ChangeModified
+		c.From, c.To = fmt.Sprint(from.DocumentCount), fmt.Sprint(to.DocumentCount)
+		c.Message = fmt.Sprintf("document count %d → %d (%s)", from.DocumentCount, to.DocumentCount, change)
+		changes = append(changes, c)
+	}
+	if change, ok := growth(from.AverageDocSizeBytes, to.AverageDocSizeBytes, opts); ok {
+		c := base
+		c.Kind, c.Change = KindDocumentSize, types.ChangeModified
+		c.From, c.To = formatBytes(from.AverageDocSizeBytes), formatBytes(to.AverageDocSizeBytes)
+		c.Message = fmt.Sprintf("average document size %s → %s (%s)", c.From, c.To, change)
+		changes = append(changes, c)
+	}
+
+	// Reports loaded without index information would otherwise show every
+	// index as added or removed
+	if !from.IndexesUnknown && !to.IndexesUnknown {
+		changes = append(changes, compareIndexes(base, from, to)...)
+	}
+	changes = append(changes, compareFields(base, from.Fields, to.Fields, opts)...)
+	return changes
+}
+
+// compareIndexes reports added, removed and redefined indexes
+func compareIndexes(base types.SchemaChange, from, to types.Collection) []types.SchemaChange {
+	var changes []types.SchemaChange
+	fromIndexes := indexesByName(from)
+	toIndexes := indexesByName(to)
+
+	// Options are only known when both reports hold index details
+	withOptions := len(from.IndexDetails) > 0 && len(to.IndexDetails) > 0
+
+	for _, name := range unionNames(fromIndexes, toIndexes) {
+		oldIndex, inOld := fromIndexes[name]
+		newIndex, inNew := toIndexes[name]
+		c := base
+		c.Kind, c.Index = KindIndex, name
+		switch {
+		case !inOld:
+			c.Change = types.ChangeAdded
+			c.To = describeIndex(newIndex, true)
+			c.Message = "index added"
+			if c.To != "" {
+				c.Message += " on " + c.To
+			}
+		case !inNew:
+			c.Change = types.ChangeRemoved
+			c.From = describeIndex(oldIndex, true)
+			c.Message = "index removed"
+		default:
+			c.From, c.To = describeIndex(oldIndex, withOptions), describeIndex(newIndex, withOptions)
+			if c.From == c.To || oldIndex.Keys == nil || newIndex.Keys == nil {
+				continue
+			}
+			c.Change = types.ChangeModified
+			c.Message = fmt.Sprintf("index redefined from %s to %s", c.From, c.To)
+		}
+		changes = append(changes, c)
+	}
+
+	return changes
+}
+
+// compareFields reports added and removed fields and changes of type and
+// presence, walking the new fields first and then the removed ones
+func compareFields(base types.SchemaChange, from, to []types.Field, opts Options) []types.SchemaChange {
+	var changes []types.SchemaChange
+	oldFields := schema.Flatten(from)
+	newFields := schema.Flatten(to)
+	oldByPath := fieldsByPath(oldFields)
+	newByPath := fieldsByPath(newFields)
+
+	added := make(map[string]bool)
+	for _, f := range newFields {
+		c := base
+		c.Path = f.Path
+
+		old, ok := oldByPath[f.Path]
+		if !ok {
+			added[f.Path] = true
+			if added[schema.ParentPath(f.Path)] {
+				continue
+			}
+			c.Kind, c.Change = KindField, types.ChangeAdded
+			c.To = f.Field.InferredType
+			c.Message = fmt.Sprintf("field added (%s, %.1f%% presence)", f.Field.InferredType, f.Field.PresencePercent)
+			changes = append(changes, c)
+			continue
+		}
+
+		if old.InferredType != f.Field.InferredType {
+			c := c
+			c.Kind, c.Change = KindInferredType, types.ChangeModified
+			c.From, c.To = old.InferredType, f.Field.InferredType
+			c.Breaking = true
+			c.Message = fmt.Sprintf("inferred type changed from %s to %s", old.InferredType, f.Field.InferredType)
+			changes = append(changes, c)
+		}
+
+		if shifts := typeShifts(old.Types, f.Field.Types, opts.TypeThreshold); len(shifts) > 0 {
+			c := c
+			c.Kind, c.Change = KindTypeDistribution, types.ChangeModified
+			c.From, c.To = schema.TypeDistribution(old.Types), schema.TypeDistribution(f.Field.Types)
+			c.Message = "type distribution shifted: " + strings.Join(shifts, ", ")
+			changes = append(changes, c)
+		}
+
+		if delta := f.Field.PresencePercent - old.PresencePercent; math.Abs(delta) >= opts.PresenceThreshold && delta != 0 {
+			c := c
+			c.Kind, c.Change = KindPresence, types.ChangeModified
+			c.From, c.To = fmt.Sprintf("%.1f%%", old.PresencePercent), fmt.Sprintf("%.1f%%", f.Field.PresencePercent)
+			c.Message = fmt.Sprintf("presence %s → %s (%+.1f)", c.From, c.To, delta)
+			changes = append(changes, c)
+		}
+	}
+
+	removed := make(map[string]bool)
+	for _, f := range oldFields {
+		if _, ok := newByPath[f.Path]; ok {
+			continue
+		}
+		removed[f.Path] = true
+		if removed[schema.ParentPath(f.Path)] {
+			continue
+		}
+		c := base
+		c.Kind, c.Change, c.Path = KindField, types.ChangeRemoved, f.Path
+		c.From = f.Field.InferredType
+		c.Breaking = true
+		c.Message = fmt.Sprintf("field removed (was %s)", f.Field.InferredType)
+		changes = append(changes, c)
+	}
+
+	return changes
+}
+
+// typeShifts describes the types whose frequency moved by at least the
+// threshold, such as "double 10.0% → 35.0%"
+func typeShifts(from, to []types.TypeFrequency, threshold float64) []string {
+	oldPercent := make(map[string]float64)
+	newPercent := make(map[string]float64)
+	var names []string
+	for _, t := range from {
+		oldPercent[t.Type] = t.FrequencyPercent
+		names = append(names, t.Type)
+	}
+	for _, t := range to {
+		newPercent[t.Type] = t.FrequencyPercent
+		if _, ok := oldPercent[t.Type]; !ok {
+			names = append(names, t.Type)
+		}
+	}
+	sort.Strings(names)
+
+	var shifts []string
+	for _, name := range names {
+		if math.Abs(newPercent[name]-oldPercent[name]) >= threshold && newPercent[name] != oldPercent[name] {
+			shifts = append(shifts, fmt.Sprintf("%s %.1f%% → %.1f%%", name, oldPercent[name], newPercent[name]))
+		}
+	}
+	return shifts
+}
+
+// growth formats the relative change between two sizes when it reaches
+// the growth threshold
+func growth(from, to int64, opts Options) (string, bool) {
+	if from == to {
+		return "", false
+	}
+	if from == 0 {
+		return "new", true
+	}
+
+	percent := float64(to-from) / float64(from) * 100
+	if math.Abs(percent) < opts.GrowthThreshold {
+		return "", false
+	}
+	return fmt.Sprintf("%+.1f%%", percent), true
+}
+
+// describeIndex formats the key pattern of an index, with its options when
+// requested
+func describeIndex(index types.Index, withOptions bool) string {
+	keys := make([]string, 0, len(index.Keys))
+	for _, key := range index.Keys {
+		keys = append(keys, key.Field+": "+key.Order)
+	}
+	description := strings.Join(keys, ", ")
+	if description != "" {
+		description = "{" + description + "}"
+	}
+
+	if withOptions {
+		if index.Unique {
+			description += " unique"
+		}
+		if index.Sparse {
+			description += " sparse"
+		}
+		if index.PartialFilter != "" {
+			description += " partial " + index.PartialFilter
+		}
+		if index.ExpireAfterSeconds != nil {
+			description += fmt.Sprintf(" ttl %ds", *index.ExpireAfterSeconds)
+		}
+	}
+	return strings.TrimSpace(description)
+}
+
+// formatBytes formats a size in bytes with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// databasesByName indexes the databases of a scan result
+func databasesByName(result *types.ScanResult) map[string]types.Database {
+	byName := make(map[string]types.Database, len(result.Databases))
+	for _, db := range result.Databases {
+		byName[db.Name] = db
+	}
+	return byName
+}
+
+// collectionsByName indexes the collections of a database
+func collectionsByName(db types.Database) map[string]types.Collection {
+	byName := make(map[string]types.Collection, len(db.Collections))
+	for _, coll := range db.Collections {
+		byName[coll.Name] = coll
+	}
+	return byName
+}
+
+// indexesByName indexes the indexes of a collection. Reports without index
+// details only list names, and names that are not default index names give
+// indexes without keys.
+func indexesByName(coll types.Collection) map[string]types.Index {
+	byName := make(map[string]types.Index)
+	if len(coll.IndexDetails) == 0 {
+		for _, name := range coll.Indexes {
+			byName[name] = types.Index{Name: name}
+		}
+	}
+	for _, index := range coll.IndexDefinitions() {
+		byName[index.Name] = index
+	}
+	return byName
+}
+
+// fieldsByPath indexes flattened fields by path
+func fieldsByPath(fields []schema.FlatField) map[string]types.Field {
+	byPath := make(map[string]types.Field, len(fields))
+	for _, f := range fields {
+		byPath[f.Path] = f.Field
+	}
+	return byPath
+}
+
+// unionNames returns the sorted keys of two maps
+func unionNames[V any](a, b map[string]V) []string {
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,284 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"mongo-scanner/internal/types"
+)
+
+// field builds a field with a single observed type
+func field(path, typ string, presence float64, nested ...types.Field) types.Field {
+	return types.Field{
+		Path: path, InferredType: typ, PresencePercent: presence, NestedFields: nested,
+		Types: []types.TypeFrequency{{Type: typ, FrequencyPercent: 100}},
+	}
+}
+
+// array builds an array field whose elements are objects with fields
+func array(path string, presence float64, elements ...types.Field) types.Field {
+	f := field(path, "array", presence)
+	items := field("[]", "object", 100, elements...)
+	f.ArrayItems = &items
+	return f
+}
+
+// collection builds a collection of 100 documents of 200 bytes
+func collection(name string, fields ...types.Field) types.Collection {
+	return types.Collection{Name: name, DocumentCount: 100, AverageDocSizeBytes: 200, Indexes: []string{"_id_"}, Fields: fields}
+}
+
+// shop builds a scan result with a shop database holding collections
+func shop(collections ...types.Collection) *types.ScanResult {
+	return &types.ScanResult{Databases: []types.Database{{Name: "shop", Collections: collections}}}
+}
+
+// changeKeys lists changes as "kind change location: message"
+func changeKeys(changes []types.SchemaChange) []string {
+	keys := make([]string, 0, len(changes))
+	for _, c := range changes {
+		location := c.Database
+		switch {
+		case c.Path != "":
+			location = c.Path
+		case c.Index != "":
+			location = c.Index
+		case c.Collection != "":
+			location = c.Collection
+		}
+		keys = append(keys, fmt.Sprintf("%s %s %s: %s", c.Kind, c.Change, location, c.Message))
+	}
+	return keys
+}
+
+func TestCompare(t *testing.T) {
+	withCount := func(c types.Collection, count int64) types.Collection {
+		c.DocumentCount = count
+		return c
+	}
+	withSize := func(c types.Collection, size int64) types.Collection {
+		c.AverageDocSizeBytes = size
+		return c
+	}
+	withIndexes := func(c types.Collection, indexes ...string) types.Collection {
+		c.Indexes = indexes
+		return c
+	}
+	withTypes := func(f types.Field, frequencies ...types.TypeFrequency) types.Field {
+		f.Types = frequencies
+		return f
+	}
+	orders := collection("orders", field("_id", "objectId", 100), field("status", "string", 100))
+	defaults := DefaultOptions()
+
+	tests := []struct {
+		name string
+		from *types.ScanResult
+		to   *types.ScanResult
+		opts Options
+		want []string
+	}{
+		{
+			name: "unchanged",
+			from: shop(orders),
+			to:   shop(orders),
+			opts: defaults,
+			want: []string{},
+		},
+		{
+			name: "database added and removed",
+			from: shop(orders),
+			to:   &types.ScanResult{Databases: []types.Database{{Name: "crm", Collections: []types.Collection{orders}}}},
+			opts: defaults,
+			want: []string{
+				"database added crm: database added with 1 collections",
+				"database removed shop: database removed",
+			},
+		},
+		{
+			name: "database size needs both sizes",
+			from: &types.ScanResult{Databases: []types.Database{{Name: "shop", SizeBytes: 0}}},
+			to:   &types.ScanResult{Databases: []types.Database{{Name: "shop", SizeBytes: 4096}}},
+			opts: defaults,
+			want: []string{},
+		},
+		{
+			name: "database size growth",
+			from: &types.ScanResult{Databases: []types.Database{{Name: "shop", SizeBytes: 1024}}},
+			to:   &types.ScanResult{Databases: []types.Database{{Name: "shop", SizeBytes: 2048}}},
+			opts: defaults,
+			want: []string{"database-size changed shop: data size 1.0 KiB → 2.0 KiB (+100.0%)"},
+		},
+		{
+			name: "collection added and removed",
+			from: shop(orders),
+			to:   shop(collection("invoices", field("_id", "objectId", 100))),
+			opts: defaults,
+			want: []string{
+				"collection added invoices: collection added with 100 documents and 1 fields",
+				"collection removed orders: collection removed",
+			},
+		},
+		{
+			name: "document count growth from zero is new",
+			from: shop(withCount(orders, 0)),
+			to:   shop(orders),
+			opts: defaults,
+			want: []string{"document-count changed orders: document count 0 → 100 (new)"},
+		},
+		{
+			name: "document count growth below threshold",
+			from: shop(orders),
+			to:   shop(withCount(orders, 109)),
+			opts: defaults,
+			want: []string{},
+		},
+		{
+			name: "document count growth at threshold",
+			from: shop(orders),
+			to:   shop(withCount(orders, 110)),
+			opts: defaults,
+			want: []string{"document-count changed orders: document count 100 → 110 (+10.0%)"},
+		},
+		{
+			name: "document size shrink",
+			from: shop(orders),
+			to:   shop(withSize(orders, 100)),
+			opts: defaults,
+			want: []string{"document-size changed orders: average document size 200 B → 100 B (-50.0%)"},
+		},
+		{
+			name: "indexes added and removed",
+			from: shop(withIndexes(orders, "_id_", "status_1")),
+			to:   shop(withIndexes(orders, "_id_", "created_-1")),
+			opts: defaults,
+			want: []string{
+				"index added created_-1: index added on {created: -1}",
+				"index removed status_1: index removed",
+			},
+		},
+		{
+			name: "indexes of a report without index information",
+			from: shop(withIndexes(orders, "_id_", "status_1")),
+			to: func() *types.ScanResult {
+				coll := withIndexes(orders)
+				coll.IndexesUnknown = true
+				return shop(coll)
+			}(),
+			opts: defaults,
+			want: []string{},
+		},
+		{
+			name: "field added and removed",
+			from: shop(collection("orders", field("_id", "objectId", 100), field("legacy", "string", 40))),
+			to:   shop(collection("orders", field("_id", "objectId", 100), field("status", "string", 100))),
+			opts: defaults,
+			want: []string{
+				"field added status: field added (string, 100.0% presence)",
+				"field removed legacy: field removed (was string)",
+			},
+		},
+		{
+			name: "children of added and removed objects are not listed",
+			from: shop(collection("orders", field("billing", "object", 100, field("city", "string", 100)))),
+			to:   shop(collection("orders", field("shipping", "object", 100, field("city", "string", 100)))),
+			opts: defaults,
+			want: []string{
+				"field added shipping: field added (object, 100.0% presence)",
+				"field removed billing: field removed (was object)",
+			},
+		},
+		{
+			name: "element fields of existing arrays",
+			from: shop(collection("orders", array("items", 100, field("sku", "string", 100)))),
+			to:   shop(collection("orders", array("items", 100, field("qty", "int32", 100)))),
+			opts: defaults,
+			want: []string{
+				"field added items[].qty: field added (int32, 100.0% presence)",
+				"field removed items[].sku: field removed (was string)",
+			},
+		},
+		{
+			name: "inferred type changed",
+			from: shop(collection("orders", field("total", "string", 100))),
+			to:   shop(collection("orders", field("total", "double", 100))),
+			opts: Options{TypeThreshold: 101},
+			want: []string{"inferred-type changed total: inferred type changed from string to double"},
+		},
+		{
+			name: "type distribution shift at threshold",
+			from: shop(collection("orders", withTypes(field("total", "double", 100), types.TypeFrequency{Type: "double", FrequencyPercent: 100}))),
+			to: shop(collection("orders", withTypes(field("total", "double", 100),
+				types.TypeFrequency{Type: "double", FrequencyPercent: 95}, types.TypeFrequency{Type: "int32", FrequencyPercent: 5}))),
+			opts: Options{TypeThreshold: 5},
+			want: []string{"type-distribution changed total: type distribution shifted: double 100.0% → 95.0%, int32 0.0% → 5.0%"},
+		},
+		{
+			name: "type distribution shift below threshold",
+			from: shop(collection("orders", withTypes(field("total", "double", 100), types.TypeFrequency{Type: "double", FrequencyPercent: 100}))),
+			to: shop(collection("orders", withTypes(field("total", "double", 100),
+				types.TypeFrequency{Type: "double", FrequencyPercent: 96}, types.TypeFrequency{Type: "int32", FrequencyPercent: 4}))),
+			opts: Options{TypeThreshold: 5},
+			want: []string{},
+		},
+		{
+			name: "presence change at threshold",
+			from: shop(collection("orders", field("note", "string", 50))),
+			to:   shop(collection("orders", field("note", "string", 45))),
+			opts: Options{PresenceThreshold: 5},
+			want: []string{"presence changed note: presence 50.0% → 45.0% (-5.0)"},
+		},
+		{
+			name: "presence change below threshold",
+			from: shop(collection("orders", field("note", "string", 50))),
+			to:   shop(collection("orders", field("note", "string", 46))),
+			opts: Options{PresenceThreshold: 5},
+			want: []string{},
+		},
+		{
+			name: "zero presence threshold reports any change",
+			from: shop(collection("orders", field("note", "string", 50))),
+			to:   shop(collection("orders", field("note", "string", 50.5))),
+			opts: Options{},
+			want: []string{"presence changed note: presence 50.0% → 50.5% (+0.5)"},
+		},
+		{
+			name: "zero thresholds ignore unchanged values",
+			from: shop(orders),
+			to:   shop(orders),
+			opts: Options{},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := changeKeys(Compare(tt.from, tt.to, tt.opts))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("changes =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareBreaking(t *testing.T) {
+	from := shop(collection("orders", field("total", "string", 100), field("legacy", "string", 100)))
+	to := shop(collection("orders", field("total", "double", 100), field("status", "string", 100)))
+
+	breaking := make(map[string]bool)
+	for _, c := range Compare(from, to, DefaultOptions()) {
+		breaking[c.Kind+" "+c.Path] = c.Breaking
+	}
+	want := map[string]bool{
+		"inferred-type total":     true,
+		"field legacy":            true,
+		"field status":            false,
+		"type-distribution total": false,
+	}
+	for key, b := range want {
+		if got, ok := breaking[key]; !ok || got != b {
+			t.Errorf("%s breaking = %v (reported %v), want %v", key, got, ok, b)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package exporter
+
+import (
+	"io"
+	"regexp"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// Decimal128 values have up to 34 significant digits. Formats that need a
+// fixed scale use decimalScale fractional digits.
+const (
+	decimalPrecision = 34
+	decimalScale     = 10
+)
+
+// avroInvalidChars matches characters that are not allowed in Avro names
+var avroInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// AvroExporter exports each collection as an Avro record schema. Optional
+// and nullable fields are unions with null, dates and decimals use logical
+// types, and mixed fields follow the fallback policy of columnKind.
+type AvroExporter struct {
+	RequiredThreshold float64
+	Split             bool
+}
+
+// Export writes all record schemas, keyed by "<db>.<collection>"
+func (e *AvroExporter) Export(result *types.ScanResult, w io.Writer) error {
+	schemas := make(map[string]interface{})
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			schemas[db.Name+"."+coll.Name] = e.CollectionSchema(db, coll)
+		}
+	}
+
+	return encodeJSON(w, schemas)
+}
+
+// ExportToFile writes all schemas to a file, or one .avsc file per
+// collection into the directory at filepath when Split is set
+func (e *AvroExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	if e.Split {
+		return writeSplitFiles(result, filepath, ".avsc", func(db types.Database, coll types.Collection, w io.Writer) error {
+			return encodeJSON(w, e.CollectionSchema(db, coll))
+		})
+	}
+
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// CollectionSchema builds the Avro record schema of a collection
+func (e *AvroExporter) CollectionSchema(db types.Database, coll types.Collection) map[string]interface{} {
+	b := avroBuilder{requiredThreshold: e.RequiredThreshold, names: make(map[string]bool)}
+
+	name := schema.UniqueName(avroName(naming.Pascal(naming.Singular(coll.Name)), "Document"), b.names)
+	record := b.record(name, coll.Fields, nil)
+	record["namespace"] = avroName(naming.Snake(db.Name), "db")
+	record["doc"] = "Document of the " + db.Name + "." + coll.Name + " collection"
+	return record
+}
+
+// avroBuilder maps field trees onto Avro schemas. Record names must be
+// unique within a schema, so nested records are named after their parent.
+type avroBuilder struct {
+	requiredThreshold float64
+	names             map[string]bool
+}
+
+// record builds a record schema for the fields of object, or for the
+// top-level fields when object is nil
+func (b avroBuilder) record(name string, fields []types.Field, object *types.Field) map[string]interface{} {
+	fieldNames := make(map[string]bool)
+	avroFields := make([]interface{}, 0, len(fields))
+
+	for _, f := range fields {
+		field := map[string]interface{}{
+			"name": schema.UniqueName(avroName(f.Path, "field"), fieldNames),
+		}
+		if field["name"] != f.Path {
+			field["doc"] = "Source key: " + f.Path
+		}
+
+		typ := b.fieldType(name, f)
+		if !schema.Required(f, object, b.requiredThreshold) || schema.Nullable(f) {
+			field["type"] = []interface{}{"null", typ}
+			field["default"] = nil
+		} else {
+			field["type"] = typ
+		}
+
+		avroFields = append(avroFields, field)
+	}
+
+	return map[string]interface{}{
+		"type":   "record",
+		"name":   name,
+		"fields": avroFields,
+	}
+}
+
+// fieldType maps a field onto an Avro type, without the null branch
+func (b avroBuilder) fieldType(parent string, f types.Field) interface{} {
+	switch kind := columnKind(f); kind {
+	case "string", "regex", "objectId":
+		return "string"
+	case "int32":
+		return "int"
+	case "int64", "timestamp":
+		return "long"
+	case "double":
+		return "double"
+	case "boolean":
+		return "boolean"
+	case "date":
+		return map[string]interface{}{"type": "long", "logicalType": "timestamp-millis"}
+	case "decimal":
+		return map[string]interface{}{
+			"type":        "bytes",
+			"logicalType": "decimal",
+			"precision":   decimalPrecision,
+			"scale":       decimalScale,
+		}
+	case "binData":
+		return "bytes"
+	case "object":
+		name := schema.UniqueName(parent+avroName(naming.Pascal(f.Path), "Record"), b.names)
+		return b.record(name, f.NestedFields, &f)
+	case "array":
+		if f.ArrayItems == nil {
+			return map[string]interface{}{"type": "array", "items": "string"}
+		}
+		items := schema.Elements(f, naming.Singular(f.Path))
+		itemType := b.fieldType(parent, items)
+		if schema.Nullable(items) {
+			itemType = []interface{}{"null", itemType}
+		}
+		return map[string]interface{}{"type": "array", "items": itemType}
+	default:
+		// Mixed and opaque values are carried as Extended JSON strings
+		return "string"
+	}
+}
+
+// avroName replaces characters that are not valid in Avro names
+func avroName(key, fallback string) string {
+	name := avroInvalidChars.ReplaceAllString(key, "_")
+	switch {
+	case name == "":
+		return fallback
+	case name[0] >= '0' && name[0] <= '9':
+		return "_" + name
+	default:
+		return name
+	}
+}
@@ -4,86 +4,286 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
-	"os"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"mongo-scanner/internal/schema"
 	"mongo-scanner/internal/types"
 )
 
-// CSVExporter exports scan results to CSV format
-type CSVExporter struct{}
+// CSVExporter exports scan results to CSV format. The zero value writes
+// the default columns, comma-separated, with one row per field.
+type CSVExporter struct {
+	// Columns are the names of the columns to write, from CSVColumns
+	Columns []string
+	// Delimiter separates values; zero means a comma
+	Delimiter rune
+	// Long writes one row per field and observed type instead of one row
+	// per field
+	Long bool
+	// Split writes one CSV file per collection into a directory
+	Split bool
+}
+
+// csvRow is the data a CSV row is rendered from
+type csvRow struct {
+	Database   types.Database
+	Collection types.Collection
+	schema.FlatField
+	// Type is the observed type of a long format row, nil in wide format
+	Type *types.TypeFrequency
+	// Indexes maps index key fields to the names of their indexes
+	Indexes map[string][]string
+}
+
+// csvColumn is a column that can be selected for the CSV export
+type csvColumn struct {
+	Header string
+	Value  func(r csvRow) string
+	// LongOnly columns describe a single type and need long format
+	LongOnly bool
+}
+
+// csvColumns are the available columns by name
+var csvColumns = map[string]csvColumn{
+	"database":          {Header: "Database", Value: func(r csvRow) string { return r.Database.Name }},
+	"collection":        {Header: "Collection", Value: func(r csvRow) string { return r.Collection.Name }},
+	"document_count":    {Header: "Document Count", Value: func(r csvRow) string { return strconv.FormatInt(r.Collection.DocumentCount, 10) }},
+	"avg_doc_size":      {Header: "Avg Doc Size (bytes)", Value: func(r csvRow) string { return strconv.FormatInt(r.Collection.AverageDocSizeBytes, 10) }},
+	"path":              {Header: "Field Path", Value: func(r csvRow) string { return r.Path }},
+	"depth":             {Header: "Depth", Value: func(r csvRow) string { return strconv.Itoa(r.Depth) }},
+	"inferred_type":     {Header: "Inferred Type", Value: func(r csvRow) string { return r.Field.InferredType }},
+	"presence":          {Header: "Presence %", Value: func(r csvRow) string { return fmt.Sprintf("%.1f", r.Field.PresencePercent) }},
+	"type_distribution": {Header: "Type Distribution", Value: func(r csvRow) string { return schema.TypeDistribution(r.Field.Types) }},
+	"nullable":          {Header: "Nullable", Value: func(r csvRow) string { return strconv.FormatBool(schema.Nullable(r.Field)) }},
+	"null_percent":      {Header: "Null %", Value: func(r csvRow) string { return fmt.Sprintf("%.1f", typePercent(r.Field, "null")) }},
+	"enum":              {Header: "Enum Values", Value: func(r csvRow) string { return strings.Join(r.Field.Enum, "|") }},
+	"array_item_type":   {Header: "Array Item Type", Value: csvArrayItemType},
+	"array_non_empty":   {Header: "Array Non-Empty %", Value: csvArrayNonEmpty},
+	"indexes":           {Header: "Indexes", Value: func(r csvRow) string { return strings.Join(r.Indexes[indexPath(r.Path)], "|") }},
+	"type":              {Header: "Type", Value: csvType, LongOnly: true},
+	"type_percent":      {Header: "Type %", Value: csvTypePercent, LongOnly: true},
+}
+
+// defaultCSVColumns are the columns written when none are selected
+var defaultCSVColumns = []string{
+	"database", "collection", "document_count", "avg_doc_size",
+	"path", "inferred_type", "presence", "type_distribution",
+}
+
+// defaultLongCSVColumns are the columns written in long format when none
+// are selected
+var defaultLongCSVColumns = []string{
+	"database", "collection", "document_count", "avg_doc_size",
+	"path", "inferred_type", "presence", "type", "type_percent",
+}
+
+// CSVColumns returns the names of the available CSV columns
+func CSVColumns() []string {
+	return []string{
+		"database", "collection", "document_count", "avg_doc_size",
+		"path", "depth", "inferred_type", "presence", "type_distribution",
+		"nullable", "null_percent", "enum", "array_item_type", "array_non_empty",
+		"indexes", "type", "type_percent",
+	}
+}
+
+// Validate checks the column selection and delimiter
+func (e *CSVExporter) Validate() error {
+	for _, name := range e.Columns {
+		column, ok := csvColumns[name]
+		if !ok {
+			return fmt.Errorf("unknown CSV column: %s. Valid columns: %v", name, CSVColumns())
+		}
+		if column.LongOnly && !e.Long {
+			return fmt.Errorf("CSV column %s needs the long format", name)
+		}
+	}
+
+	switch e.Delimiter {
+	case '"', '\r', '\n', utf8.RuneError:
+		return fmt.Errorf("invalid CSV delimiter: %q", e.Delimiter)
+	}
+	return nil
+}
 
 // Export writes the scan result as CSV
 func (e *CSVExporter) Export(result *types.ScanResult, w io.Writer) error {
-	writer := csv.NewWriter(w)
-	defer writer.Flush()
-
-	// Write header
-	header := []string{
-		"Database",
-		"Collection",
-		"Document Count",
-		"Avg Doc Size (bytes)",
-		"Field Path",
-		"Inferred Type",
-		"Presence %",
-		"Type Distribution",
-	}
-	if err := writer.Write(header); err != nil {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	writer := e.newWriter(w)
+	if err := writer.Write(e.header()); err != nil {
 		return err
 	}
 
-	// Write data
 	for _, db := range result.Databases {
 		for _, coll := range db.Collections {
-			e.writeFields(writer, db.Name, coll.Name, coll.DocumentCount, coll.AverageDocSizeBytes, coll.Fields, "")
+			if err := e.writeFields(writer, db, coll); err != nil {
+				return err
+			}
 		}
 	}
 
-	return nil
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportToFile writes the scan result to a CSV file, or one CSV file per
+// collection into the directory at filepath when Split is set
+func (e *CSVExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	if e.Split {
+		return writeSplitFiles(result, filepath, ".csv", func(db types.Database, coll types.Collection, w io.Writer) error {
+			writer := e.newWriter(w)
+			if err := writer.Write(e.header()); err != nil {
+				return err
+			}
+			if err := e.writeFields(writer, db, coll); err != nil {
+				return err
+			}
+			writer.Flush()
+			return writer.Error()
+		})
+	}
+
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
 }
 
-// writeFields recursively writes fields to CSV
-func (e *CSVExporter) writeFields(writer *csv.Writer, dbName, collName string, docCount, avgSize int64, fields []types.Field, prefix string) {
-	for _, field := range fields {
-		path := field.Path
-		if prefix != "" {
-			path = prefix + "." + field.Path
+// newWriter creates a CSV writer with the configured delimiter
+func (e *CSVExporter) newWriter(w io.Writer) *csv.Writer {
+	writer := csv.NewWriter(w)
+	if e.Delimiter != 0 {
+		writer.Comma = e.Delimiter
+	}
+	return writer
+}
+
+// columns returns the selected columns
+func (e *CSVExporter) columns() []csvColumn {
+	names := e.Columns
+	if len(names) == 0 {
+		names = defaultCSVColumns
+		if e.Long {
+			names = defaultLongCSVColumns
 		}
+	}
 
-		// Build type distribution string
-		var typeStrs []string
-		for _, t := range field.Types {
-			typeStrs = append(typeStrs, fmt.Sprintf("%s:%.1f%%", t.Type, t.FrequencyPercent))
+	columns := make([]csvColumn, len(names))
+	for i, name := range names {
+		columns[i] = csvColumns[name]
+	}
+	return columns
+}
+
+// header returns the header row
+func (e *CSVExporter) header() []string {
+	columns := e.columns()
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Header
+	}
+	return header
+}
+
+// writeFields writes the rows of a collection: one per field, or one per
+// field and observed type in long format
+func (e *CSVExporter) writeFields(writer *csv.Writer, db types.Database, coll types.Collection) error {
+	columns := e.columns()
+	indexes := indexesByField(coll)
+
+	write := func(row csvRow) error {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = column.Value(row)
 		}
-		typeDist := strings.Join(typeStrs, ", ")
-
-		row := []string{
-			dbName,
-			collName,
-			fmt.Sprintf("%d", docCount),
-			fmt.Sprintf("%d", avgSize),
-			path,
-			field.InferredType,
-			fmt.Sprintf("%.1f", field.PresencePercent),
-			typeDist,
+		return writer.Write(record)
+	}
+
+	for _, field := range schema.Flatten(coll.Fields) {
+		row := csvRow{Database: db, Collection: coll, FlatField: field, Indexes: indexes}
+		if !e.Long || len(field.Field.Types) == 0 {
+			if err := write(row); err != nil {
+				return err
+			}
+			continue
 		}
-		writer.Write(row)
 
-		// Write nested fields
-		if len(field.NestedFields) > 0 {
-			e.writeFields(writer, dbName, collName, docCount, avgSize, field.NestedFields, path)
+		for i := range field.Field.Types {
+			row.Type = &field.Field.Types[i]
+			if err := write(row); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
-// ExportToFile writes the scan result to a CSV file
-func (e *CSVExporter) ExportToFile(result *types.ScanResult, filepath string) error {
-	f, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filepath, err)
+// typePercent returns the frequency of an observed type of a field
+func typePercent(field types.Field, typeName string) float64 {
+	for _, t := range field.Types {
+		if t.Type == typeName {
+			return t.FrequencyPercent
+		}
 	}
-	defer f.Close()
+	return 0
+}
+
+// indexesByField maps the key fields of a collection's indexes to the
+// names of the indexes that include them
+func indexesByField(coll types.Collection) map[string][]string {
+	byField := make(map[string][]string)
+	for _, index := range coll.IndexDefinitions() {
+		for _, key := range index.Keys {
+			byField[key.Field] = append(byField[key.Field], index.Name)
+		}
+	}
+	return byField
+}
 
-	return e.Export(result, f)
+// indexPath converts a row path into the dot notation of index keys,
+// which reaches into array elements without a segment ("items[].sku" is
+// indexed as "items.sku")
+func indexPath(path string) string {
+	return strings.ReplaceAll(path, "[]", "")
+}
+
+// csvArrayItemType returns the element type of an array field
+func csvArrayItemType(r csvRow) string {
+	if r.Field.ArrayItems == nil {
+		return ""
+	}
+	return r.Field.ArrayItems.InferredType
+}
+
+// csvArrayNonEmpty returns the share of non-empty arrays of an array field
+func csvArrayNonEmpty(r csvRow) string {
+	if r.Field.ArrayItems == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", r.Field.ArrayItems.PresencePercent)
+}
+
+// csvType returns the observed type of a long format row
+func csvType(r csvRow) string {
+	if r.Type == nil {
+		return ""
+	}
+	return r.Type.Type
+}
+
+// csvTypePercent returns the frequency of the observed type of a long
+// format row
+func csvTypePercent(r csvRow) string {
+	if r.Type == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", r.Type.FrequencyPercent)
 }
@@ -0,0 +1,192 @@
+package exporter
+
+import (
+	"mongo-scanner/internal/analyzer"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// diagram is the entity-relationship model shared by the diagram exporters.
+// Collections and embedded documents are entities; embedded documents are
+// linked to their parent by composition edges and inferred references by
+// reference edges.
+type diagram struct {
+	Entities []diagramEntity
+	Edges    []diagramEdge
+}
+
+// diagramEntity is a collection or an embedded document
+type diagramEntity struct {
+	// ID is a unique identifier safe for every diagram language
+	ID string
+	// Label is "<db>.<collection>" for collections and the dotted path
+	// within the collection for embedded documents
+	Label      string
+	Embedded   bool
+	Attributes []diagramAttribute
+}
+
+// diagramAttribute is a field of an entity
+type diagramAttribute struct {
+	Name string
+	Type string
+	// Key is "PK" for _id, "FK" for inferred references, or empty
+	Key      string
+	Optional bool
+}
+
+// diagramEdge links two entities
+type diagramEdge struct {
+	From, To string
+	// Label is the field holding the embedded document or reference
+	Label     string
+	Reference bool
+	Many      bool
+	// Optional reports whether the source field may be absent
+	Optional bool
+}
+
+// buildDiagram builds the diagram of a scan. Attributes are required when
+// their presence reaches requiredThreshold and they are never null.
+func buildDiagram(result *types.ScanResult, requiredThreshold float64) diagram {
+	b := diagramBuilder{
+		requiredThreshold: requiredThreshold,
+		ids:               make(map[string]bool),
+	}
+
+	// Collections are registered first so embedded documents never take
+	// the identifier of a collection
+	collections := make(map[string]string)
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			name := coll.Name
+			if len(result.Databases) > 1 {
+				name = db.Name + "_" + coll.Name
+			}
+			collections[db.Name+"."+coll.Name] = schema.UniqueName(avroName(name, "collection"), b.ids)
+		}
+	}
+
+	for _, db := range result.Databases {
+		b.entities = make(map[string]string)
+		refs := make(map[string]types.Reference)
+		for _, ref := range analyzer.InferReferences(db) {
+			if _, ok := collections[db.Name+"."+ref.TargetCollection]; ok && ref.TargetCollection != "" {
+				refs[ref.Collection+"."+ref.Path] = ref
+			}
+		}
+
+		for _, coll := range db.Collections {
+			id := collections[db.Name+"."+coll.Name]
+			b.entity(id, db.Name+"."+coll.Name, false, coll.Fields, nil, coll.Name, "", refs)
+		}
+
+		for _, coll := range db.Collections {
+			for _, f := range coll.Fields {
+				b.referenceEdges(f, nil, coll.Name, "", refs, collections, db.Name)
+			}
+		}
+	}
+
+	return b.diagram
+}
+
+// diagramBuilder accumulates entities and edges
+type diagramBuilder struct {
+	requiredThreshold float64
+	ids               map[string]bool
+	// entities maps "<collection>.<path>" of the embedded documents of the
+	// current database to their ID
+	entities map[string]string
+	diagram  diagram
+}
+
+// entity adds an entity for a field list and, recursively, the embedded
+// documents among its fields. object is the field the entity was built from,
+// nil for the collection itself and array elements, and path is the dotted
+// path of the entity within the collection, empty for the collection itself.
+func (b *diagramBuilder) entity(id, label string, embedded bool, fields []types.Field, object *types.Field, collection, path string, refs map[string]types.Reference) {
+	index := len(b.diagram.Entities)
+	b.diagram.Entities = append(b.diagram.Entities, diagramEntity{ID: id, Label: label, Embedded: embedded})
+
+	var attributes []diagramAttribute
+	for _, f := range fields {
+		fieldPath := joinPath(path, f.Path, ".")
+		optional := !schema.Required(f, object, b.requiredThreshold) || schema.Nullable(f)
+
+		attribute := diagramAttribute{Name: f.Path, Type: diagramType(f), Optional: optional}
+		if _, ok := refs[collection+"."+fieldPath]; ok {
+			attribute.Key = "FK"
+		} else if path == "" && f.Path == "_id" {
+			attribute.Key = "PK"
+		}
+		attributes = append(attributes, attribute)
+
+		// Embedded documents, directly or as array elements
+		nested, nestedIn, many := f.NestedFields, &f, false
+		for items := f.ArrayItems; items != nil && len(nested) == 0; items = items.ArrayItems {
+			nested, nestedIn, many = items.NestedFields, nil, true
+		}
+		if len(nested) == 0 {
+			continue
+		}
+
+		childID := schema.UniqueName(avroName(id+"_"+f.Path, "embedded"), b.ids)
+		b.entities[collection+"."+fieldPath] = childID
+		b.diagram.Edges = append(b.diagram.Edges, diagramEdge{
+			From:     id,
+			To:       childID,
+			Label:    f.Path,
+			Many:     many,
+			Optional: optional,
+		})
+		b.entity(childID, collection+"."+fieldPath, true, nested, nestedIn, collection, fieldPath, refs)
+	}
+
+	b.diagram.Entities[index].Attributes = attributes
+}
+
+// referenceEdges adds the reference edges of a field and its nested fields.
+// object is the field f is nested in, or nil.
+func (b *diagramBuilder) referenceEdges(f types.Field, object *types.Field, collection, path string, refs map[string]types.Reference, collections map[string]string, db string) {
+	fieldPath := joinPath(path, f.Path, ".")
+	if ref, ok := refs[collection+"."+fieldPath]; ok {
+		from := collections[db+"."+collection]
+		if path != "" {
+			from = b.entities[collection+"."+path]
+		}
+		b.diagram.Edges = append(b.diagram.Edges, diagramEdge{
+			From:      from,
+			To:        collections[db+"."+ref.TargetCollection],
+			Label:     f.Path,
+			Reference: true,
+			Many:      ref.Many,
+			Optional:  !schema.Required(f, object, b.requiredThreshold) || schema.Nullable(f),
+		})
+	}
+
+	for _, nested := range f.NestedFields {
+		b.referenceEdges(nested, &f, collection, fieldPath, refs, collections, db)
+	}
+	for items := f.ArrayItems; items != nil; items = items.ArrayItems {
+		for _, nested := range items.NestedFields {
+			b.referenceEdges(nested, nil, collection, fieldPath, refs, collections, db)
+		}
+	}
+}
+
+// diagramType describes the type of a field: the inferred type, with "[]"
+// appended for each array level
+func diagramType(f types.Field) string {
+	suffix := ""
+	for f.InferredType == "array" && f.ArrayItems != nil {
+		suffix += "[]"
+		f = *f.ArrayItems
+	}
+
+	typ := f.InferredType
+	if typ == "" {
+		typ = "unknown"
+	}
+	return typ + suffix
+}
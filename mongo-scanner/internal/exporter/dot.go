@@ -0,0 +1,87 @@
+package exporter
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"mongo-scanner/internal/types"
+)
+
+// DOTExporter exports the collections as a Graphviz digraph with one
+// table-shaped node per entity. Embedded documents hang off their parent
+// with a diamond (composition) and inferred references are dashed edges.
+type DOTExporter struct {
+	RequiredThreshold float64
+}
+
+// Export writes the graph
+func (e *DOTExporter) Export(result *types.ScanResult, w io.Writer) error {
+	d := buildDiagram(result, e.RequiredThreshold)
+
+	var b strings.Builder
+	b.WriteString("digraph schema {\n")
+	fmt.Fprintf(&b, "  graph [rankdir=LR, label=%s, labelloc=t];\n", dotString(result.ClusterName))
+	b.WriteString("  node [shape=plain, fontname=\"Helvetica\", fontsize=10];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=9];\n")
+
+	for _, entity := range d.Entities {
+		header := "#d0e0f0"
+		if entity.Embedded {
+			header = "#eeeeee"
+		}
+
+		var label strings.Builder
+		label.WriteString(`<table border="0" cellborder="1" cellspacing="0" cellpadding="4">`)
+		fmt.Fprintf(&label, `<tr><td bgcolor="%s" colspan="2"><b>%s</b></td></tr>`, header, html.EscapeString(entity.Label))
+		for _, attribute := range entity.Attributes {
+			name := html.EscapeString(attribute.Name)
+			if attribute.Key != "" {
+				name += " (" + attribute.Key + ")"
+			}
+			if !attribute.Optional {
+				name = "<b>" + name + "</b>"
+			}
+			fmt.Fprintf(&label, `<tr><td align="left">%s</td><td align="left">%s</td></tr>`, name, html.EscapeString(attribute.Type))
+		}
+		label.WriteString("</table>")
+
+		fmt.Fprintf(&b, "  %s [label=<%s>];\n", entity.ID, label.String())
+	}
+
+	for _, edge := range d.Edges {
+		if edge.Reference {
+			fmt.Fprintf(&b, "  %s -> %s [label=%s, style=dashed, arrowhead=%s];\n",
+				edge.From, edge.To, dotString(edge.Label), dotReferenceArrow(edge))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s, headlabel=%s, dir=both, arrowtail=diamond, arrowhead=none];\n",
+			edge.From, edge.To, dotString(edge.Label), dotString(diagramMultiplicity(edge)))
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ExportToFile writes the graph to a file
+func (e *DOTExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// dotReferenceArrow returns the arrowhead of a reference edge: crow's foot
+// for arrays of references
+func dotReferenceArrow(edge diagramEdge) string {
+	if edge.Many {
+		return "crow"
+	}
+	return "normal"
+}
+
+// dotString quotes a string for the DOT language
+func dotString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -3,7 +3,11 @@ package exporter
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"mongo-scanner/internal/output"
 	"mongo-scanner/internal/types"
 )
 
@@ -11,9 +15,25 @@ import (
 type Format string
 
 const (
-	FormatJSON Format = "json"
-	FormatYAML Format = "yaml"
-	FormatCSV  Format = "csv"
+	FormatJSON        Format = "json"
+	FormatYAML        Format = "yaml"
+	FormatCSV         Format = "csv"
+	FormatJSONSchema  Format = "jsonschema"
+	FormatValidator   Format = "mongo-validator"
+	FormatPostgres    Format = "postgres"
+	FormatMySQL       Format = "mysql"
+	FormatAvro        Format = "avro"
+	FormatProtobuf    Format = "protobuf"
+	FormatParquet     Format = "parquet"
+	FormatGraphQL     Format = "graphql"
+	FormatOpenAPI     Format = "openapi"
+	FormatMarkdown    Format = "markdown"
+	FormatHTML        Format = "html"
+	FormatMermaid     Format = "mermaid"
+	FormatPlantUML    Format = "plantuml"
+	FormatDOT         Format = "dot"
+	FormatStructurizr Format = "structurizr"
+	FormatXLSX        Format = "xlsx"
 )
 
 // Exporter interface for all export formats
@@ -22,15 +42,89 @@ type Exporter interface {
 	ExportToFile(result *types.ScanResult, filepath string) error
 }
 
+// Options configures exporters that derive schemas from a scan
+type Options struct {
+	// RequiredThreshold is the presence percentage at or above which
+	// a field is treated as required
+	RequiredThreshold float64
+	// Split writes one file per collection into the output directory
+	// instead of a single bundle, for formats that support it
+	Split bool
+	// CSV configures the CSV exporter
+	CSV CSVOptions
+}
+
+// CSVOptions selects the columns, delimiter and layout of CSV exports
+type CSVOptions struct {
+	// Columns are the names of the columns to write, from CSVColumns;
+	// empty writes the default columns
+	Columns []string
+	// Delimiter separates values; zero means a comma
+	Delimiter rune
+	// Long writes one row per field and observed type
+	Long bool
+}
+
+// DefaultOptions returns default exporter options
+func DefaultOptions() Options {
+	return Options{
+		RequiredThreshold: 100,
+	}
+}
+
 // NewExporter creates an exporter based on format
 func NewExporter(format Format) (Exporter, error) {
+	return NewExporterWithOptions(format, DefaultOptions())
+}
+
+// NewExporterWithOptions creates an exporter based on format and options
+func NewExporterWithOptions(format Format, opts Options) (Exporter, error) {
 	switch format {
 	case FormatJSON:
 		return &JSONExporter{Pretty: true}, nil
 	case FormatYAML:
 		return &YAMLExporter{}, nil
 	case FormatCSV:
-		return &CSVExporter{}, nil
+		exp := &CSVExporter{
+			Columns:   opts.CSV.Columns,
+			Delimiter: opts.CSV.Delimiter,
+			Long:      opts.CSV.Long,
+			Split:     opts.Split,
+		}
+		if err := exp.Validate(); err != nil {
+			return nil, err
+		}
+		return exp, nil
+	case FormatJSONSchema:
+		return &JSONSchemaExporter{RequiredThreshold: opts.RequiredThreshold, Split: opts.Split}, nil
+	case FormatValidator:
+		return &ValidatorExporter{RequiredThreshold: opts.RequiredThreshold, Split: opts.Split}, nil
+	case FormatPostgres, FormatMySQL:
+		return &SQLExporter{Dialect: format, RequiredThreshold: opts.RequiredThreshold, Split: opts.Split}, nil
+	case FormatAvro:
+		return &AvroExporter{RequiredThreshold: opts.RequiredThreshold, Split: opts.Split}, nil
+	case FormatProtobuf:
+		return &ProtobufExporter{RequiredThreshold: opts.RequiredThreshold, Split: opts.Split}, nil
+	case FormatParquet:
+		return &ParquetExporter{RequiredThreshold: opts.RequiredThreshold, Split: opts.Split}, nil
+	case FormatGraphQL:
+		return &GraphQLExporter{RequiredThreshold: opts.RequiredThreshold}, nil
+	case FormatOpenAPI:
+		return &OpenAPIExporter{RequiredThreshold: opts.RequiredThreshold}, nil
+	case FormatMarkdown:
+		return &MarkdownExporter{Split: opts.Split}, nil
+	case FormatHTML:
+		return &HTMLExporter{}, nil
+	case FormatMermaid:
+		return &MermaidExporter{RequiredThreshold: opts.RequiredThreshold}, nil
+	case FormatPlantUML:
+		return &PlantUMLExporter{RequiredThreshold: opts.RequiredThreshold}, nil
+	case FormatDOT:
+		return &DOTExporter{RequiredThreshold: opts.RequiredThreshold}, nil
+	case FormatStructurizr:
+		return &StructurizrExporter{}, nil
+	case FormatXLSX:
+		return &XLSXExporter{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported format: %s", format)
 	}
@@ -38,5 +132,122 @@ func NewExporter(format Format) (Exporter, error) {
 
 // ValidFormats returns list of valid export formats
 func ValidFormats() []string {
-	return []string{string(FormatJSON), string(FormatYAML), string(FormatCSV)}
+	return []string{
+		string(FormatJSON),
+		string(FormatYAML),
+		string(FormatCSV),
+		string(FormatJSONSchema),
+		string(FormatValidator),
+		string(FormatPostgres),
+		string(FormatMySQL),
+		string(FormatAvro),
+		string(FormatProtobuf),
+		string(FormatParquet),
+		string(FormatGraphQL),
+		string(FormatOpenAPI),
+		string(FormatMarkdown),
+		string(FormatHTML),
+		string(FormatMermaid),
+		string(FormatPlantUML),
+		string(FormatDOT),
+		string(FormatStructurizr),
+		string(FormatXLSX),
+	}
+}
+
+// writeFile writes an output location through the output package: a local
+// file, "-" for stdout or a registered remote destination, compressed when
+// the location ends in .gz or .zst. Local files are replaced atomically,
+// so a failed export never leaves a truncated file behind.
+func writeFile(path string, write func(w io.Writer) error) error {
+	w, err := output.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(w); err != nil {
+		w.Abort()
+		return err
+	}
+	return w.Commit()
+}
+
+// formatExtensions are the file extensions of each format, distinct so
+// that several formats can be written next to each other
+var formatExtensions = map[Format]string{
+	FormatJSON:        ".json",
+	FormatYAML:        ".yaml",
+	FormatCSV:         ".csv",
+	FormatJSONSchema:  ".schema.json",
+	FormatValidator:   ".validator.json",
+	FormatPostgres:    ".postgres.sql",
+	FormatMySQL:       ".mysql.sql",
+	FormatAvro:        ".avro.json",
+	FormatProtobuf:    ".proto",
+	FormatParquet:     ".parquet.schema",
+	FormatGraphQL:     ".graphql",
+	FormatOpenAPI:     ".openapi.yaml",
+	FormatMarkdown:    ".md",
+	FormatHTML:        ".html",
+	FormatMermaid:     ".mmd",
+	FormatPlantUML:    ".puml",
+	FormatDOT:         ".dot",
+	FormatStructurizr: ".dsl",
+	FormatXLSX:        ".xlsx",
+}
+
+// Extension returns the file extension of a format
+func Extension(format Format) string {
+	if ext, ok := formatExtensions[format]; ok {
+		return ext
+	}
+	return "." + string(format)
+}
+
+// SupportsSplit reports whether a format writes one file per collection
+// into a directory when Options.Split is set
+func SupportsSplit(format Format) bool {
+	switch format {
+	case FormatCSV, FormatJSONSchema, FormatValidator, FormatPostgres, FormatMySQL,
+		FormatAvro, FormatProtobuf, FormatParquet, FormatMarkdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeSplitFiles writes one file per collection into dir, named
+// "<database>.<collection><suffix>"
+func writeSplitFiles(result *types.ScanResult, dir, suffix string, write func(db types.Database, coll types.Collection, w io.Writer) error) error {
+	if !output.IsLocal(dir) {
+		return fmt.Errorf("split output needs a local directory, got %s", dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			path := filepath.Join(dir, safeFileName(db.Name+"."+coll.Name)+suffix)
+			err := writeFile(path, func(w io.Writer) error {
+				return write(db, coll, w)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// safeFileName replaces characters that are not safe in file names
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '_'
+		}
+		return r
+	}, name)
 }
@@ -0,0 +1,233 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// graphQLNamePattern matches valid GraphQL names
+var graphQLNamePattern = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// graphQLScalars are the custom scalars generated types may use, with
+// their descriptions
+var graphQLScalars = map[string]string{
+	"ObjectId": "MongoDB ObjectId as a 24-character hex string",
+	"DateTime": "Date and time as an ISO 8601 string",
+	"Decimal":  "Decimal128 value as a decimal string",
+	"Long":     "64-bit integer",
+	"JSON":     "Arbitrary JSON value, used for mixed and untyped fields",
+}
+
+// GraphQLExporter exports the collections as GraphQL SDL object types.
+// Inferred references get an extra field linking to the referenced type.
+type GraphQLExporter struct {
+	RequiredThreshold float64
+}
+
+// graphQLWriter renders the types of a schema and records the scalars used
+type graphQLWriter struct {
+	requiredThreshold float64
+	names             map[string]bool
+	scalars           map[string]bool
+	types             bytes.Buffer
+}
+
+// Export writes the SDL of every collection
+func (e *GraphQLExporter) Export(result *types.ScanResult, w io.Writer) error {
+	g := &graphQLWriter{
+		requiredThreshold: e.RequiredThreshold,
+		names:             make(map[string]bool),
+		scalars:           make(map[string]bool),
+	}
+
+	typeNames := collectionTypeNames(result)
+	for _, name := range typeNames {
+		g.names[name] = true
+	}
+
+	for _, db := range result.Databases {
+		links := relationships(db, typeNames)
+		for _, coll := range db.Collections {
+			doc := "Document of the " + db.Name + "." + coll.Name + " collection"
+			g.objectType(typeNames[db.Name+"."+coll.Name], doc, coll.Fields, nil, "", links[coll.Name])
+		}
+	}
+
+	// A line break in the cluster name would end the comment
+	if _, err := fmt.Fprintf(w, "# GraphQL schema of %s generated by mongo-scanner\n", strings.Join(strings.Fields(result.ClusterName), " ")); err != nil {
+		return err
+	}
+
+	scalars := make([]string, 0, len(g.scalars))
+	for scalar := range g.scalars {
+		scalars = append(scalars, scalar)
+	}
+	sort.Strings(scalars)
+	for _, scalar := range scalars {
+		if _, err := fmt.Fprintf(w, "\n%s\nscalar %s\n", graphQLString(graphQLScalars[scalar]), scalar); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(g.types.Bytes())
+	return err
+}
+
+// ExportToFile writes the SDL to a file
+func (e *GraphQLExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// objectType renders an object type for the fields of object, or for the
+// top-level fields when object is nil. Nested objects and enums become
+// further types named after their parent; path is the dotted path of the
+// object, used to place relationship fields.
+func (g *graphQLWriter) objectType(name, doc string, fields []types.Field, object *types.Field, path string, links []relationship) {
+	var body bytes.Buffer
+	fieldNames := make(map[string]bool)
+
+	for _, f := range fields {
+		fieldPath := joinPath(path, f.Path, ".")
+		fieldName := schema.UniqueName(graphQLName(f.Path), fieldNames)
+
+		typ := g.fieldType(name, f, fieldPath, links)
+		if schema.Required(f, object, g.requiredThreshold) && !schema.Nullable(f) {
+			typ += "!"
+		}
+
+		if fieldName != f.Path {
+			fmt.Fprintf(&body, "  %s\n", graphQLString("Source key: "+f.Path))
+		}
+		fmt.Fprintf(&body, "  %s: %s\n", fieldName, typ)
+
+		for _, link := range links {
+			if link.Path != fieldPath {
+				continue
+			}
+			linkType := link.Target
+			if link.Many {
+				linkType = "[" + link.Target + "!]"
+			}
+			fmt.Fprintf(&body, "  %s\n", graphQLString(link.Target+" resolved from "+f.Path))
+			fmt.Fprintf(&body, "  %s: %s\n", schema.UniqueName(graphQLName(link.Name), fieldNames), linkType)
+		}
+	}
+
+	fmt.Fprintf(&g.types, "\n%s\ntype %s {\n", graphQLString(doc), name)
+	g.types.Write(body.Bytes())
+	g.types.WriteString("}\n")
+}
+
+// fieldType maps a field onto a GraphQL type, without the non-null marker
+func (g *graphQLWriter) fieldType(parent string, f types.Field, path string, links []relationship) string {
+	if enum := g.enumType(parent, f); enum != "" {
+		return enum
+	}
+
+	switch kind := columnKind(f); kind {
+	case "string", "regex":
+		return "String"
+	case "int32":
+		return "Int"
+	case "double":
+		return "Float"
+	case "boolean":
+		return "Boolean"
+	case "binData":
+		return "String"
+	case "object":
+		name := schema.UniqueName(parent+graphQLTypeName(f.Path), g.names)
+		g.objectType(name, "The "+f.Path+" object of "+parent, f.NestedFields, &f, path, links)
+		return name
+	case "array":
+		if f.ArrayItems == nil {
+			g.scalars["JSON"] = true
+			return "[JSON]"
+		}
+		items := schema.Elements(f, naming.Singular(f.Path))
+		typ := g.fieldType(parent, items, path, links)
+		if !schema.Nullable(items) {
+			typ += "!"
+		}
+		return "[" + typ + "]"
+	default:
+		scalar := graphQLScalar(kind)
+		g.scalars[scalar] = true
+		return scalar
+	}
+}
+
+// enumType renders an enum type for a field with detected enum values
+// that are all valid GraphQL names, and returns its name
+func (g *graphQLWriter) enumType(parent string, f types.Field) string {
+	if len(f.Enum) == 0 {
+		return ""
+	}
+	for _, v := range f.Enum {
+		if !graphQLNamePattern.MatchString(v) || v == "true" || v == "false" || v == "null" {
+			return ""
+		}
+	}
+
+	name := schema.UniqueName(parent+graphQLTypeName(f.Path), g.names)
+	fmt.Fprintf(&g.types, "\n%s\nenum %s {\n", graphQLString("Values of "+parent+"."+f.Path), name)
+	for _, v := range f.Enum {
+		fmt.Fprintf(&g.types, "  %s\n", v)
+	}
+	g.types.WriteString("}\n")
+	return name
+}
+
+// graphQLString returns a GraphQL string value for a description. JSON string
+// escapes are all valid in GraphQL strings, so quotes, backslashes and
+// control characters in keys and names keep the SDL valid.
+func graphQLString(s string) string {
+	out, _ := json.Marshal(s)
+	return string(out)
+}
+
+// graphQLScalar maps a column kind onto a custom scalar
+func graphQLScalar(kind string) string {
+	switch kind {
+	case "objectId":
+		return "ObjectId"
+	case "date":
+		return "DateTime"
+	case "decimal":
+		return "Decimal"
+	case "int64", "timestamp":
+		return "Long"
+	default:
+		return "JSON"
+	}
+}
+
+// graphQLName makes a key a valid GraphQL field name. Names starting with
+// two underscores are reserved for introspection.
+func graphQLName(key string) string {
+	name := avroName(key, "field")
+	if strings.HasPrefix(name, "__") {
+		name = strings.TrimLeft(name, "_")
+		if name == "" {
+			name = "field"
+		}
+		name = "_" + name
+	}
+	return name
+}
+
+// graphQLTypeName converts a key into a PascalCase type name
+func graphQLTypeName(key string) string {
+	return avroName(naming.Pascal(key), "Type")
+}
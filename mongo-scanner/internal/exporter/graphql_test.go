@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"mongo-scanner/internal/types"
+)
+
+func TestGraphQLEscapesDescriptions(t *testing.T) {
+	result := &types.ScanResult{
+		ClusterName: "prod\ncluster",
+		Databases: []types.Database{{
+			Name: "shop",
+			Collections: []types.Collection{{
+				Name: `say"hi`,
+				Fields: []types.Field{
+					{Path: `a"b\c`, InferredType: "string", PresencePercent: 100},
+					{Path: "tab\tkey", InferredType: "string", PresencePercent: 100},
+				},
+			}},
+		}},
+	}
+
+	var b bytes.Buffer
+	if err := (&GraphQLExporter{RequiredThreshold: 100}).Export(result, &b); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	sdl := b.String()
+
+	for _, want := range []string{
+		"# GraphQL schema of prod cluster generated by mongo-scanner\n",
+		`"Document of the shop.say\"hi collection"`,
+		`"Source key: a\"b\\c"`,
+		`"Source key: tab\tkey"`,
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("SDL does not contain %s:\n%s", want, sdl)
+		}
+	}
+}
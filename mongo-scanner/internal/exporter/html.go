@@ -0,0 +1,115 @@
+package exporter
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+
+	"mongo-scanner/internal/types"
+)
+
+// htmlTemplate is the single-page report; CSS and JS are inlined so the
+// file works offline
+//
+//go:embed html_report.tmpl
+var htmlTemplate string
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(htmlTemplate))
+
+// HTMLExporter exports scan results as a self-contained HTML report with
+// collapsible trees, type distribution bars and search
+type HTMLExporter struct{}
+
+// htmlReport is the data rendered by the report template
+type htmlReport struct {
+	ClusterName   string
+	ScanTimestamp string
+	Databases     []htmlDatabase
+	Collections   int
+	Fields        int
+}
+
+type htmlDatabase struct {
+	Name        string
+	SizeBytes   int64
+	Collections []htmlCollection
+}
+
+type htmlCollection struct {
+	Name                string
+	DocumentCount       int64
+	AverageDocSizeBytes int64
+	Indexes             []string
+	FieldCount          int
+	Fields              []htmlField
+}
+
+type htmlField struct {
+	Name            string
+	Path            string
+	InferredType    string
+	PresencePercent float64
+	Types           []types.TypeFrequency
+	Children        []htmlField
+}
+
+// Export writes the HTML report
+func (e *HTMLExporter) Export(result *types.ScanResult, w io.Writer) error {
+	report := htmlReport{
+		ClusterName:   result.ClusterName,
+		ScanTimestamp: result.ScanTimestamp,
+	}
+
+	for _, db := range result.Databases {
+		database := htmlDatabase{Name: db.Name, SizeBytes: db.SizeBytes}
+		for _, coll := range db.Collections {
+			fieldCount := types.CountFields(coll.Fields)
+			database.Collections = append(database.Collections, htmlCollection{
+				Name:                coll.Name,
+				DocumentCount:       coll.DocumentCount,
+				AverageDocSizeBytes: coll.AverageDocSizeBytes,
+				Indexes:             coll.Indexes,
+				FieldCount:          fieldCount,
+				Fields:              htmlFields(coll.Fields, ""),
+			})
+			report.Collections++
+			report.Fields += fieldCount
+		}
+		report.Databases = append(report.Databases, database)
+	}
+
+	return htmlReportTemplate.Execute(w, report)
+}
+
+// ExportToFile writes the HTML report to a file
+func (e *HTMLExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// htmlFields converts a field tree into template nodes. Array elements
+// become a "[]" child holding the element fields.
+func htmlFields(fields []types.Field, prefix string) []htmlField {
+	nodes := make([]htmlField, 0, len(fields))
+	for _, f := range fields {
+		path := joinPath(prefix, f.Path, ".")
+		if f.Path == "[]" {
+			path = prefix + "[]"
+		}
+		node := htmlField{
+			Name:            f.Path,
+			Path:            path,
+			InferredType:    f.InferredType,
+			PresencePercent: f.PresencePercent,
+			Types:           f.Types,
+			Children:        htmlFields(f.NestedFields, path),
+		}
+		if f.ArrayItems != nil {
+			items := *f.ArrayItems
+			node.Children = append(node.Children, htmlFields([]types.Field{items}, path)...)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
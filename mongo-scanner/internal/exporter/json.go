@@ -2,9 +2,7 @@ package exporter
 
 import (
 	"encoding/json"
-	"fmt"
 	"io"
-	"os"
 
 	"mongo-scanner/internal/types"
 )
@@ -25,11 +23,7 @@ func (e *JSONExporter) Export(result *types.ScanResult, w io.Writer) error {
 
 // ExportToFile writes the scan result to a JSON file
 func (e *JSONExporter) ExportToFile(result *types.ScanResult, filepath string) error {
-	f, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filepath, err)
-	}
-	defer f.Close()
-
-	return e.Export(result, f)
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
 }
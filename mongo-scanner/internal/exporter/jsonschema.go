@@ -0,0 +1,201 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// jsonSchemaDraft is the meta-schema of generated JSON Schema documents
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchemaExporter exports each collection as a JSON Schema (draft 2020-12)
+// document, either bundled under $defs or as one file per collection
+type JSONSchemaExporter struct {
+	RequiredThreshold float64
+	Split             bool
+}
+
+// Export writes a bundle with one $defs entry per collection
+func (e *JSONSchemaExporter) Export(result *types.ScanResult, w io.Writer) error {
+	defs := make(map[string]interface{})
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			node := e.CollectionSchema(db, coll)
+			delete(node, "$schema")
+			defs[db.Name+"."+coll.Name] = node
+		}
+	}
+
+	bundle := map[string]interface{}{
+		"$schema": jsonSchemaDraft,
+		"title":   result.ClusterName,
+		"$defs":   defs,
+	}
+
+	return encodeJSON(w, bundle)
+}
+
+// ExportToFile writes the bundle to a file, or one schema per collection
+// into the directory at filepath when Split is set
+func (e *JSONSchemaExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	if e.Split {
+		return writeSplitFiles(result, filepath, ".schema.json", func(db types.Database, coll types.Collection, w io.Writer) error {
+			return encodeJSON(w, e.CollectionSchema(db, coll))
+		})
+	}
+
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// CollectionSchema builds the JSON Schema document of a collection
+func (e *JSONSchemaExporter) CollectionSchema(db types.Database, coll types.Collection) map[string]interface{} {
+	builder := schemaBuilder{requiredThreshold: e.RequiredThreshold}
+
+	node := map[string]interface{}{
+		"$schema":     jsonSchemaDraft,
+		"$id":         db.Name + "." + coll.Name + ".schema.json",
+		"title":       coll.Name,
+		"description": fmt.Sprintf("Schema of %s.%s inferred from sampled documents", db.Name, coll.Name),
+		"type":        "object",
+	}
+	builder.addProperties(node, coll.Fields, nil)
+	return node
+}
+
+// schemaBuilder maps field trees onto JSON Schema. With bsonTypes set it
+// emits the MongoDB $jsonSchema dialect, which uses bsonType instead of
+// type and does not support format keywords.
+type schemaBuilder struct {
+	requiredThreshold float64
+	bsonTypes         bool
+}
+
+// addProperties fills properties and required from the fields nested in
+// parent, or the top-level fields when parent is nil
+func (b schemaBuilder) addProperties(node map[string]interface{}, fields []types.Field, parent *types.Field) {
+	if len(fields) == 0 {
+		return
+	}
+
+	properties := make(map[string]interface{}, len(fields))
+	required := []string{}
+	for _, f := range fields {
+		properties[f.Path] = b.fieldSchema(f)
+		if schema.Required(f, parent, b.requiredThreshold) {
+			required = append(required, f.Path)
+		}
+	}
+
+	node["properties"] = properties
+	if len(required) > 0 {
+		node["required"] = required
+	}
+}
+
+// fieldSchema maps a field's inferred types onto a schema
+func (b schemaBuilder) fieldSchema(f types.Field) map[string]interface{} {
+	node := make(map[string]interface{})
+	bsonTypes := schema.Types(f)
+
+	var typeNames []string
+	for _, bsonType := range bsonTypes {
+		typeName, extra := b.typeFor(bsonType)
+		if typeName == "" {
+			continue
+		}
+		if !slices.Contains(typeNames, typeName) {
+			typeNames = append(typeNames, typeName)
+		}
+		// Formats only apply when the field has a single type
+		if len(bsonTypes) == 1 {
+			for k, v := range extra {
+				node[k] = v
+			}
+		}
+	}
+
+	if slices.Contains(bsonTypes, "object") {
+		b.addProperties(node, f.NestedFields, &f)
+	}
+	if slices.Contains(bsonTypes, "array") && f.ArrayItems != nil {
+		node["items"] = b.fieldSchema(schema.Elements(f, f.ArrayItems.Path))
+	}
+
+	nullable := schema.Nullable(f)
+	if nullable {
+		typeNames = append(typeNames, "null")
+	}
+
+	typeKey := "type"
+	if b.bsonTypes {
+		typeKey = "bsonType"
+	}
+	switch len(typeNames) {
+	case 0:
+	case 1:
+		node[typeKey] = typeNames[0]
+	default:
+		node[typeKey] = typeNames
+	}
+
+	if len(f.Enum) > 0 {
+		enum := make([]interface{}, 0, len(f.Enum)+1)
+		for _, v := range f.Enum {
+			enum = append(enum, v)
+		}
+		if nullable {
+			enum = append(enum, nil)
+		}
+		node["enum"] = enum
+	}
+
+	return node
+}
+
+// typeFor maps a BSON type name to the builder's type name and extra keywords
+func (b schemaBuilder) typeFor(bsonType string) (string, map[string]interface{}) {
+	if b.bsonTypes {
+		return mongoBSONType(bsonType), nil
+	}
+	return jsonSchemaType(bsonType)
+}
+
+// jsonSchemaType maps a BSON type name to a JSON Schema type and format keywords
+func jsonSchemaType(bsonType string) (string, map[string]interface{}) {
+	switch bsonType {
+	case "string", "regex":
+		return "string", nil
+	case "objectId":
+		return "string", map[string]interface{}{"pattern": "^[0-9a-fA-F]{24}$"}
+	case "date":
+		return "string", map[string]interface{}{"format": "date-time"}
+	case "binData":
+		return "string", map[string]interface{}{"contentEncoding": "base64"}
+	case "int32", "int64", "int", "long", "timestamp":
+		return "integer", nil
+	case "double", "decimal":
+		return "number", nil
+	case "boolean", "bool":
+		return "boolean", nil
+	case "object":
+		return "object", nil
+	case "array":
+		return "array", nil
+	default:
+		return "", nil
+	}
+}
+
+// encodeJSON writes v as indented JSON
+func encodeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
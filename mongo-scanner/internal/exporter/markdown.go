@@ -0,0 +1,218 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// markdownIndexFile is the name of the table of contents written into the
+// output directory in split mode
+const markdownIndexFile = "README.md"
+
+// MarkdownExporter exports scan results as a Markdown data dictionary with
+// one section per database and collection
+type MarkdownExporter struct {
+	Split bool
+}
+
+// Export writes the data dictionary as a single document with a table of
+// contents linking to each section
+func (e *MarkdownExporter) Export(result *types.ScanResult, w io.Writer) error {
+	var b strings.Builder
+	anchors := make(map[string]int)
+
+	writeDictionaryHeader(&b, result)
+
+	// Anchors are assigned in heading order, so sections are resolved
+	// before the table of contents is rendered
+	var toc strings.Builder
+	var body strings.Builder
+	for _, db := range result.Databases {
+		dbHeading := "Database " + db.Name
+		fmt.Fprintf(&toc, "- [%s](#%s)\n", markdownText(db.Name), markdownAnchor(dbHeading, anchors))
+		fmt.Fprintf(&body, "\n## %s\n", markdownText(dbHeading))
+
+		for _, coll := range db.Collections {
+			collHeading := db.Name + "." + coll.Name
+			fmt.Fprintf(&toc, "  - [%s](#%s)\n", markdownText(coll.Name), markdownAnchor(collHeading, anchors))
+			fmt.Fprintf(&body, "\n### %s\n\n", markdownText(collHeading))
+			writeCollectionDictionary(&body, coll, "####")
+		}
+	}
+
+	b.WriteString("\n## Contents\n\n")
+	b.WriteString(toc.String())
+	b.WriteString(body.String())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ExportToFile writes the data dictionary to a file, or one .md file per
+// collection plus a README.md table of contents into the directory at
+// filepath when Split is set
+func (e *MarkdownExporter) ExportToFile(result *types.ScanResult, path string) error {
+	if !e.Split {
+		return writeFile(path, func(w io.Writer) error {
+			return e.Export(result, w)
+		})
+	}
+
+	err := writeSplitFiles(result, path, ".md", func(db types.Database, coll types.Collection, w io.Writer) error {
+		var b strings.Builder
+		fmt.Fprintf(&b, "# %s\n\n", markdownText(db.Name+"."+coll.Name))
+		fmt.Fprintf(&b, "[Contents](%s)\n\n", markdownIndexFile)
+		writeCollectionDictionary(&b, coll, "##")
+		_, err := io.WriteString(w, b.String())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", path, err)
+	}
+	return writeFile(filepath.Join(path, markdownIndexFile), func(w io.Writer) error {
+		var b strings.Builder
+		writeDictionaryHeader(&b, result)
+		b.WriteString("\n## Contents\n\n")
+		for _, db := range result.Databases {
+			fmt.Fprintf(&b, "- %s\n", markdownText(db.Name))
+			for _, coll := range db.Collections {
+				file := safeFileName(db.Name+"."+coll.Name) + ".md"
+				fmt.Fprintf(&b, "  - [%s](%s)\n", markdownText(coll.Name), file)
+			}
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	})
+}
+
+// writeDictionaryHeader writes the title and scan summary
+func writeDictionaryHeader(b *strings.Builder, result *types.ScanResult) {
+	collections := 0
+	for _, db := range result.Databases {
+		collections += len(db.Collections)
+	}
+
+	fmt.Fprintf(b, "# Data Dictionary: %s\n\n", markdownText(result.ClusterName))
+	fmt.Fprintf(b, "Scanned %s: %d databases, %d collections.\n", result.ScanTimestamp, len(result.Databases), collections)
+}
+
+// writeCollectionDictionary writes the stats, index and field tables of a
+// collection, under subheadings of the given level
+func writeCollectionDictionary(b *strings.Builder, coll types.Collection, heading string) {
+	// Index names that do not follow the default naming scheme are listed
+	// without keys
+	indexes := coll.IndexDetails
+	if len(indexes) == 0 {
+		for _, name := range coll.Indexes {
+			indexes = append(indexes, types.Index{Name: name, Keys: types.ParseIndexName(name)})
+		}
+	}
+
+	b.WriteString("| Statistic | Value |\n")
+	b.WriteString("|-----------|-------|\n")
+	fmt.Fprintf(b, "| Documents | %d |\n", coll.DocumentCount)
+	fmt.Fprintf(b, "| Average size | %d bytes |\n", coll.AverageDocSizeBytes)
+	fmt.Fprintf(b, "| Indexes | %d |\n", len(indexes))
+
+	if len(indexes) > 0 {
+		fmt.Fprintf(b, "\n%s Indexes\n\n", heading)
+		b.WriteString("| Name | Keys | Options |\n")
+		b.WriteString("|------|------|---------|\n")
+		for _, index := range indexes {
+			keys := make([]string, 0, len(index.Keys))
+			for _, key := range index.Keys {
+				keys = append(keys, key.Field+": "+key.Order)
+			}
+			fmt.Fprintf(b, "| `%s` | `%s` | %s |\n",
+				markdownCell(index.Name), markdownCell(strings.Join(keys, ", ")), markdownCell(indexOptions(index)))
+		}
+	}
+
+	fmt.Fprintf(b, "\n%s Fields\n\n", heading)
+	if len(coll.Fields) == 0 {
+		b.WriteString("No fields were observed.\n")
+		return
+	}
+	b.WriteString("| Path | Type | Type Distribution | Presence |\n")
+	b.WriteString("|------|------|-------------------|----------|\n")
+	writeDictionaryFields(b, coll.Fields)
+}
+
+// writeDictionaryFields writes field rows, indenting nested fields by depth.
+// Array elements are listed as "path[]" followed by their element fields.
+func writeDictionaryFields(b *strings.Builder, fields []types.Field) {
+	for _, f := range schema.Flatten(fields) {
+		distribution := make([]string, 0, len(f.Field.Types))
+		for _, t := range f.Field.Types {
+			distribution = append(distribution, fmt.Sprintf("%s %.1f%%", t.Type, t.FrequencyPercent))
+		}
+
+		fmt.Fprintf(b, "| %s`%s` | %s | %s | %.1f%% |\n",
+			strings.Repeat("&nbsp;&nbsp;", f.Depth), markdownCell(f.Path), f.Field.InferredType,
+			strings.Join(distribution, ", "), f.Field.PresencePercent)
+	}
+}
+
+// indexOptions describes the options of an index definition
+func indexOptions(index types.Index) string {
+	var options []string
+	if index.Unique {
+		options = append(options, "unique")
+	}
+	if index.Sparse {
+		options = append(options, "sparse")
+	}
+	if index.ExpireAfterSeconds != nil {
+		options = append(options, fmt.Sprintf("TTL %ds", *index.ExpireAfterSeconds))
+	}
+	if index.PartialFilter != "" {
+		options = append(options, "partial "+index.PartialFilter)
+	}
+	return strings.Join(options, ", ")
+}
+
+// markdownAnchor returns the anchor GitHub-flavored Markdown assigns to a
+// heading, suffixed like GitHub when the same heading appears again
+func markdownAnchor(heading string, seen map[string]int) string {
+	anchor := strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '-'
+		case r == '-' || r == '_' || unicode.IsDigit(r):
+			return r
+		case unicode.IsLetter(r):
+			return unicode.ToLower(r)
+		default:
+			return -1
+		}
+	}, heading)
+
+	n := seen[anchor]
+	seen[anchor] = n + 1
+	if n > 0 {
+		anchor = fmt.Sprintf("%s-%d", anchor, n)
+	}
+	return anchor
+}
+
+// markdownText escapes characters with a meaning in Markdown text
+func markdownText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;").Replace(s)
+}
+
+// markdownCell makes text safe to place inside a Markdown table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,103 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"mongo-scanner/internal/types"
+)
+
+// mermaidInvalidChars matches characters that are not allowed in Mermaid
+// attribute types and names
+var mermaidInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_\-\[\]()]`)
+
+// MermaidExporter exports the collections as a Mermaid erDiagram.
+// Embedded documents are identifying relationships (solid lines) and
+// inferred references non-identifying ones (dotted lines).
+type MermaidExporter struct {
+	RequiredThreshold float64
+}
+
+// Export writes the diagram
+func (e *MermaidExporter) Export(result *types.ScanResult, w io.Writer) error {
+	d := buildDiagram(result, e.RequiredThreshold)
+
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, entity := range d.Entities {
+		fmt.Fprintf(&b, "    %s[%q] {\n", entity.ID, entity.Label)
+		for _, attribute := range entity.Attributes {
+			name := mermaidWord(attribute.Name, "field")
+			line := fmt.Sprintf("        %s %s", mermaidWord(attribute.Type, "unknown"), name)
+			if attribute.Key != "" {
+				line += " " + attribute.Key
+			}
+
+			var notes []string
+			if name != attribute.Name {
+				notes = append(notes, "key "+attribute.Name)
+			}
+			if attribute.Optional {
+				notes = append(notes, "optional")
+			}
+			if len(notes) > 0 {
+				line += fmt.Sprintf(" %q", strings.ReplaceAll(strings.Join(notes, ", "), `"`, "'"))
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("    }\n")
+	}
+
+	for _, edge := range d.Edges {
+		fmt.Fprintf(&b, "    %s %s %s : %q\n", edge.From, mermaidRelationship(edge), edge.To, strings.ReplaceAll(edge.Label, `"`, "'"))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ExportToFile writes the diagram to a file
+func (e *MermaidExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// mermaidRelationship renders the cardinality and line style of an edge
+func mermaidRelationship(edge diagramEdge) string {
+	if edge.Reference {
+		switch {
+		case edge.Many:
+			return "}o..o{"
+		case edge.Optional:
+			return "}o..o|"
+		default:
+			return "}o..||"
+		}
+	}
+
+	switch {
+	case edge.Many:
+		return "||--o{"
+	case edge.Optional:
+		return "||--o|"
+	default:
+		return "||--||"
+	}
+}
+
+// mermaidWord replaces characters that are not allowed in attribute types
+// and names, which must also start with a letter or underscore
+func mermaidWord(s, fallback string) string {
+	word := mermaidInvalidChars.ReplaceAllString(s, "_")
+	switch {
+	case word == "":
+		return fallback
+	case word[0] == '_' || (word[0] >= 'A' && word[0] <= 'Z') || (word[0] >= 'a' && word[0] <= 'z'):
+		return word
+	default:
+		return "_" + word
+	}
+}
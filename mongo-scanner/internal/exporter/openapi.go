@@ -0,0 +1,133 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"mongo-scanner/internal/output"
+	"mongo-scanner/internal/types"
+)
+
+// openAPIVersion is the OpenAPI version of generated documents, the first
+// to use JSON Schema 2020-12 for schema objects
+const openAPIVersion = "3.1.0"
+
+// OpenAPIExporter exports the collections as OpenAPI components.schemas.
+// Inferred references get a read-only property that links to the schema
+// of the referenced collection.
+type OpenAPIExporter struct {
+	RequiredThreshold float64
+}
+
+// Export writes the OpenAPI document as JSON
+func (e *OpenAPIExporter) Export(result *types.ScanResult, w io.Writer) error {
+	return encodeJSON(w, e.Document(result))
+}
+
+// ExportToFile writes the OpenAPI document to a file, as YAML when the
+// file has a .yaml or .yml extension (before any compression extension)
+// and as JSON otherwise
+func (e *OpenAPIExporter) ExportToFile(result *types.ScanResult, path string) error {
+	switch strings.ToLower(filepath.Ext(strings.TrimSuffix(path, output.CompressionExt(path)))) {
+	case ".yaml", ".yml":
+		return writeFile(path, func(w io.Writer) error {
+			encoder := yaml.NewEncoder(w)
+			encoder.SetIndent(2)
+			return encoder.Encode(e.Document(result))
+		})
+	}
+
+	return writeFile(path, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// Document builds an OpenAPI document without paths whose components hold
+// one schema per collection
+func (e *OpenAPIExporter) Document(result *types.ScanResult) map[string]interface{} {
+	builder := schemaBuilder{requiredThreshold: e.RequiredThreshold}
+	typeNames := collectionTypeNames(result)
+
+	schemas := make(map[string]interface{})
+	for _, db := range result.Databases {
+		links := relationships(db, typeNames)
+		for _, coll := range db.Collections {
+			schema := map[string]interface{}{
+				"type":        "object",
+				"description": fmt.Sprintf("Document of the %s.%s collection", db.Name, coll.Name),
+			}
+			builder.addProperties(schema, coll.Fields, nil)
+
+			for _, link := range links[coll.Name] {
+				addRelationship(schema, link)
+			}
+			schemas[typeNames[db.Name+"."+coll.Name]] = schema
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":       result.ClusterName,
+			"version":     result.ScanTimestamp,
+			"description": "Schemas inferred by mongo-scanner from sampled documents",
+		},
+		"paths": map[string]interface{}{},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+// addRelationship adds a read-only property referencing the target schema
+// next to the reference field it was resolved from
+func addRelationship(schema map[string]interface{}, link relationship) {
+	parent := schema
+	segments := strings.Split(link.Path, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		parent = propertySchema(parent, segment)
+		if parent == nil {
+			return
+		}
+	}
+
+	properties, ok := parent["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	ref := map[string]interface{}{"$ref": "#/components/schemas/" + link.Target}
+	property := ref
+	if link.Many {
+		property = map[string]interface{}{"type": "array", "items": ref}
+	}
+	property["readOnly"] = true
+	property["description"] = fmt.Sprintf("%s resolved from %s", link.Target, segments[len(segments)-1])
+
+	name := link.Name
+	for i := 2; properties[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", link.Name, i)
+	}
+	properties[name] = property
+}
+
+// propertySchema returns the object schema of a property, looking through
+// array items, or nil when there is none
+func propertySchema(schema map[string]interface{}, key string) map[string]interface{} {
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	property, ok := properties[key].(map[string]interface{})
+	for ok {
+		if _, isObject := property["properties"]; isObject {
+			return property
+		}
+		property, ok = property["items"].(map[string]interface{})
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// parquetNameReplacer replaces characters that end a name in the Parquet
+// schema text format or are rejected by Spark and Arrow readers
+var parquetNameReplacer = strings.NewReplacer(
+	" ", "_", ",", "_", ";", "_", "{", "_", "}", "_",
+	"(", "_", ")", "_", "=", "_", "\t", "_", "\n", "_",
+)
+
+// ParquetExporter exports each collection as a Parquet message schema in
+// the text format printed by parquet-tools, which maps one-to-one onto
+// Arrow types. Mixed fields follow the fallback policy of columnKind.
+type ParquetExporter struct {
+	RequiredThreshold float64
+	Split             bool
+}
+
+// Export writes one message schema per collection
+func (e *ParquetExporter) Export(result *types.ScanResult, w io.Writer) error {
+	first := true
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			if !first {
+				if _, err := io.WriteString(w, "\n"); err != nil {
+					return err
+				}
+			}
+			first = false
+
+			if _, err := w.Write(e.CollectionSchema(coll)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ExportToFile writes all schemas to a file, or one .parquet.schema file
+// per collection into the directory at filepath when Split is set
+func (e *ParquetExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	if e.Split {
+		return writeSplitFiles(result, filepath, ".parquet.schema", func(db types.Database, coll types.Collection, w io.Writer) error {
+			_, err := w.Write(e.CollectionSchema(coll))
+			return err
+		})
+	}
+
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// CollectionSchema renders the message schema of a collection
+func (e *ParquetExporter) CollectionSchema(coll types.Collection) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "message %s {\n", parquetName(coll.Name))
+	e.writeFields(&b, coll.Fields, nil, 1)
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// writeFields renders the fields of a group. object is the field the group
+// was built from, or nil for the top-level fields.
+func (e *ParquetExporter) writeFields(b *bytes.Buffer, fields []types.Field, object *types.Field, depth int) {
+	for _, f := range fields {
+		repetition := "required"
+		if !schema.Required(f, object, e.RequiredThreshold) || schema.Nullable(f) {
+			repetition = "optional"
+		}
+		e.writeField(b, repetition, parquetName(f.Path), f, depth)
+	}
+}
+
+// writeField renders a single field with the given repetition and name
+func (e *ParquetExporter) writeField(b *bytes.Buffer, repetition, name string, f types.Field, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	switch kind := columnKind(f); kind {
+	case "object":
+		fmt.Fprintf(b, "%s%s group %s {\n", indent, repetition, name)
+		e.writeFields(b, f.NestedFields, &f, depth+1)
+		fmt.Fprintf(b, "%s}\n", indent)
+	case "array":
+		// Three-level LIST structure of the Parquet specification
+		fmt.Fprintf(b, "%s%s group %s (LIST) {\n", indent, repetition, name)
+		fmt.Fprintf(b, "%s  repeated group list {\n", indent)
+		if f.ArrayItems == nil {
+			fmt.Fprintf(b, "%s    optional binary element (JSON);\n", indent)
+		} else {
+			items := schema.Elements(f, f.ArrayItems.Path)
+			elementRepetition := "required"
+			if schema.Nullable(items) {
+				elementRepetition = "optional"
+			}
+			e.writeField(b, elementRepetition, "element", items, depth+2)
+		}
+		fmt.Fprintf(b, "%s  }\n", indent)
+		fmt.Fprintf(b, "%s}\n", indent)
+	default:
+		fmt.Fprintf(b, "%s%s %s;\n", indent, repetition, parquetPrimitive(kind, name))
+	}
+}
+
+// parquetPrimitive renders the primitive type, name and logical type
+// annotation of a column kind
+func parquetPrimitive(kind, name string) string {
+	switch kind {
+	case "string", "regex", "objectId":
+		return "binary " + name + " (STRING)"
+	case "int32":
+		return "int32 " + name
+	case "int64", "timestamp":
+		return "int64 " + name
+	case "double":
+		return "double " + name
+	case "boolean":
+		return "boolean " + name
+	case "date":
+		return "int64 " + name + " (TIMESTAMP(MILLIS,true))"
+	case "decimal":
+		return fmt.Sprintf("fixed_len_byte_array(16) %s (DECIMAL(%d,%d))", name, decimalPrecision, decimalScale)
+	case "binData":
+		return "binary " + name
+	default:
+		// Mixed and opaque values are carried as Extended JSON strings
+		return "binary " + name + " (JSON)"
+	}
+}
+
+// parquetName makes a key safe for the Parquet schema text format
+func parquetName(key string) string {
+	name := parquetNameReplacer.Replace(key)
+	if name == "" {
+		return "field"
+	}
+	return name
+}
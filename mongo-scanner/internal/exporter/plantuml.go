@@ -0,0 +1,100 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"mongo-scanner/internal/types"
+)
+
+// PlantUMLExporter exports the collections as a PlantUML entity diagram.
+// Embedded documents are compositions and inferred references use
+// information engineering notation on dotted lines.
+type PlantUMLExporter struct {
+	RequiredThreshold float64
+}
+
+// Export writes the diagram
+func (e *PlantUMLExporter) Export(result *types.ScanResult, w io.Writer) error {
+	d := buildDiagram(result, e.RequiredThreshold)
+
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+	fmt.Fprintf(&b, "title %s\n", result.ClusterName)
+	b.WriteString("hide circle\n")
+	b.WriteString("skinparam linetype ortho\n")
+
+	for _, entity := range d.Entities {
+		stereotype := ""
+		if entity.Embedded {
+			stereotype = " <<embedded>>"
+		}
+		fmt.Fprintf(&b, "\nentity %q as %s%s {\n", entity.Label, entity.ID, stereotype)
+
+		// Required attributes are marked with * and the primary key is
+		// separated from the other attributes
+		for i, attribute := range entity.Attributes {
+			marker := "*"
+			if attribute.Optional {
+				marker = ""
+			}
+			line := fmt.Sprintf("  %s%s : %s", marker, attribute.Name, attribute.Type)
+			if attribute.Key != "" {
+				line += " <<" + attribute.Key + ">>"
+			}
+			b.WriteString(line + "\n")
+			if attribute.Key == "PK" && i < len(entity.Attributes)-1 {
+				b.WriteString("  --\n")
+			}
+		}
+		b.WriteString("}\n")
+	}
+
+	if len(d.Edges) > 0 {
+		b.WriteString("\n")
+	}
+	for _, edge := range d.Edges {
+		if edge.Reference {
+			fmt.Fprintf(&b, "%s %s %s : %s\n", edge.From, plantUMLReference(edge), edge.To, edge.Label)
+			continue
+		}
+		fmt.Fprintf(&b, "%s *-- %q %s : %s\n", edge.From, diagramMultiplicity(edge), edge.To, edge.Label)
+	}
+
+	b.WriteString("@enduml\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ExportToFile writes the diagram to a file
+func (e *PlantUMLExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// plantUMLReference renders the information engineering arrow of a
+// reference edge
+func plantUMLReference(edge diagramEdge) string {
+	switch {
+	case edge.Many:
+		return "}o..o{"
+	case edge.Optional:
+		return "}o..o|"
+	default:
+		return "}o..||"
+	}
+}
+
+// diagramMultiplicity returns the UML multiplicity of the target of an edge
+func diagramMultiplicity(edge diagramEdge) string {
+	switch {
+	case edge.Many:
+		return "0..*"
+	case edge.Optional:
+		return "0..1"
+	default:
+		return "1"
+	}
+}
@@ -0,0 +1,214 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strings"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// ProtobufExporter exports each collection as a proto3 message. Nested
+// objects become nested messages, optional scalars use the optional label
+// and mixed fields follow the fallback policy of columnKind.
+type ProtobufExporter struct {
+	RequiredThreshold float64
+	Split             bool
+}
+
+// Export writes a single .proto file with one message per collection,
+// in a package named after the cluster
+func (e *ProtobufExporter) Export(result *types.ScanResult, w io.Writer) error {
+	p := &protoWriter{requiredThreshold: e.RequiredThreshold}
+	names := make(map[string]bool)
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			p.message(schema.UniqueName(protoMessageName(coll.Name), names), "Document of the "+db.Name+"."+coll.Name+" collection", coll.Fields, nil, 0)
+		}
+	}
+
+	return p.writeTo(w, avroName(naming.Snake(result.ClusterName), "mongo_scanner"))
+}
+
+// ExportToFile writes the .proto file, or one .proto file per collection
+// into the directory at filepath when Split is set
+func (e *ProtobufExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	if e.Split {
+		return writeSplitFiles(result, filepath, ".proto", func(db types.Database, coll types.Collection, w io.Writer) error {
+			p := &protoWriter{requiredThreshold: e.RequiredThreshold}
+			p.message(protoMessageName(coll.Name), "Document of the "+db.Name+"."+coll.Name+" collection", coll.Fields, nil, 0)
+			return p.writeTo(w, avroName(naming.Snake(db.Name), "db"))
+		})
+	}
+
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// protoWriter renders messages and records the imports they need
+type protoWriter struct {
+	requiredThreshold float64
+	body              bytes.Buffer
+	timestamp         bool
+}
+
+// writeTo writes the file header followed by the rendered messages
+func (p *protoWriter) writeTo(w io.Writer, pkg string) error {
+	var header bytes.Buffer
+	header.WriteString("// Field numbers are derived from the field keys and stay the same across\n")
+	header.WriteString("// scans as fields are added or removed.\n")
+	header.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&header, "package %s;\n", pkg)
+	if p.timestamp {
+		header.WriteString("\nimport \"google/protobuf/timestamp.proto\";\n")
+	}
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(p.body.Bytes())
+	return err
+}
+
+// message renders a message for the fields of object, or for the top-level
+// fields when object is nil, at the given nesting depth. Nested messages are
+// declared inside their parent.
+func (p *protoWriter) message(name, doc string, fields []types.Field, object *types.Field, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	// Nested messages are rendered into their own buffer and appended at
+	// the end of the parent message
+	var nested protoWriter
+	nested.requiredThreshold = p.requiredThreshold
+	nestedNames := make(map[string]bool)
+
+	fmt.Fprintf(&p.body, "\n%s// %s\n", indent, doc)
+	fmt.Fprintf(&p.body, "%smessage %s {\n", indent, name)
+
+	fieldNames := make(map[string]bool)
+	numbers := protoFieldNumbers(fields)
+	for i, f := range fields {
+		fieldName := schema.UniqueName(avroName(naming.Snake(f.Path), "field"), fieldNames)
+		typ, label, comment := p.fieldType(&nested, nestedNames, f, depth+1)
+
+		if label == "" && (!schema.Required(f, object, p.requiredThreshold) || schema.Nullable(f)) && isProtoScalar(typ) {
+			label = "optional "
+		}
+
+		line := fmt.Sprintf("%s  %s%s %s = %d", indent, label, typ, fieldName, numbers[i])
+		if fieldName != f.Path {
+			line += fmt.Sprintf(" [json_name = %q]", f.Path)
+		}
+		line += ";"
+		if comment != "" {
+			line += " // " + comment
+		}
+		fmt.Fprintln(&p.body, line)
+	}
+
+	p.body.Write(nested.body.Bytes())
+	p.timestamp = p.timestamp || nested.timestamp
+	fmt.Fprintf(&p.body, "%s}\n", indent)
+}
+
+// fieldType maps a field onto a proto type, a label ("repeated " or "")
+// and an optional comment. Nested messages are rendered into nested.
+func (p *protoWriter) fieldType(nested *protoWriter, names map[string]bool, f types.Field, depth int) (string, string, string) {
+	switch kind := columnKind(f); kind {
+	case "string", "regex":
+		return "string", "", ""
+	case "objectId":
+		return "string", "", "ObjectId hex string"
+	case "int32":
+		return "int32", "", ""
+	case "int64", "timestamp":
+		return "int64", "", ""
+	case "double":
+		return "double", "", ""
+	case "boolean":
+		return "bool", "", ""
+	case "date":
+		p.timestamp = true
+		return "google.protobuf.Timestamp", "", ""
+	case "decimal":
+		return "string", "", "Decimal128 as a decimal string"
+	case "binData":
+		return "bytes", "", ""
+	case "object":
+		name := schema.UniqueName(protoMessageName(f.Path), names)
+		nested.message(name, "The "+f.Path+" object", f.NestedFields, &f, depth)
+		return name, "", ""
+	case "array":
+		if f.ArrayItems == nil {
+			return "string", "repeated ", "Extended JSON elements"
+		}
+		items := schema.Elements(f, naming.Singular(f.Path))
+		typ, label, comment := p.fieldType(nested, names, items, depth)
+		if label != "" {
+			// Arrays of arrays need a wrapper message
+			name := schema.UniqueName(protoMessageName(items.Path)+"List", names)
+			fmt.Fprintf(&nested.body, "\n%s// Wrapper for nested %s arrays\n", strings.Repeat("  ", depth), f.Path)
+			fmt.Fprintf(&nested.body, "%smessage %s {\n", strings.Repeat("  ", depth), name)
+			fmt.Fprintf(&nested.body, "%s  repeated %s values = 1;\n", strings.Repeat("  ", depth), typ)
+			fmt.Fprintf(&nested.body, "%s}\n", strings.Repeat("  ", depth))
+			return name, "repeated ", comment
+		}
+		return typ, "repeated ", comment
+	default:
+		// Mixed and opaque values are carried as Extended JSON strings
+		comment := "Extended JSON"
+		if f.InferredType == "mixed" {
+			comment += " (mixed: " + strings.Join(schema.Types(f), ", ") + ")"
+		}
+		return "string", "", comment
+	}
+}
+
+// Field numbers are hashed into 1..maxProtoFieldNumber, which keeps tags at
+// three bytes or less on the wire. Numbers 19000 to 19999 are reserved by
+// the protobuf implementation.
+const (
+	maxProtoFieldNumber   = 1<<18 - 1
+	firstReservedProtoTag = 19000
+	lastReservedProtoTag  = 19999
+)
+
+// protoFieldNumbers derives a field number from each field's key, so that
+// adding or removing a field does not renumber the others and break wire
+// compatibility. Colliding keys take the next free number in field order.
+func protoFieldNumbers(fields []types.Field) []int {
+	numbers := make([]int, len(fields))
+	taken := make(map[int]bool, len(fields))
+	for i, f := range fields {
+		h := fnv.New32a()
+		h.Write([]byte(f.Path))
+		n := int(h.Sum32()%maxProtoFieldNumber) + 1
+		for taken[n] || (n >= firstReservedProtoTag && n <= lastReservedProtoTag) {
+			n = n%maxProtoFieldNumber + 1
+		}
+		taken[n] = true
+		numbers[i] = n
+	}
+	return numbers
+}
+
+// isProtoScalar reports whether a proto type is a scalar that needs the
+// optional label to track presence
+func isProtoScalar(typ string) bool {
+	switch typ {
+	case "string", "int32", "int64", "double", "bool", "bytes":
+		return true
+	default:
+		return false
+	}
+}
+
+// protoMessageName converts a key into a PascalCase message name
+func protoMessageName(key string) string {
+	return avroName(naming.Pascal(naming.Singular(key)), "Message")
+}
@@ -0,0 +1,292 @@
+package exporter
+
+import (
+	"fmt"
+	"slices"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// maxFlattenedColumns is the number of columns up to which an embedded
+// object is flattened into its parent table instead of a child table
+const maxFlattenedColumns = 8
+
+// Column kinds beyond the BSON type names of types.GetBSONTypeName
+const (
+	kindJSON     = "json"
+	kindSerial   = "serial"
+	kindPosition = "position"
+)
+
+// Mapping strategies of the relational mapping report
+const (
+	strategyColumn     = "column"
+	strategyKey        = "primary_key"
+	strategyFlattened  = "flattened"
+	strategyChildTable = "child_table"
+	strategyJSON       = "json"
+)
+
+// relTable is a table of the relational design of a collection
+type relTable struct {
+	Name       string
+	Path       string
+	Columns    []relColumn
+	PrimaryKey string
+	// ForeignKey references the parent table's primary key
+	ForeignKey *relForeignKey
+}
+
+// relForeignKey links a child table column to its parent table
+type relForeignKey struct {
+	Column      string
+	ParentTable string
+	ParentKey   string
+}
+
+// relColumn is a column with the kind of value it stores: a BSON type
+// name, or one of the json, serial and position kinds
+type relColumn struct {
+	Name    string
+	Kind    string
+	NotNull bool
+	Enum    []string
+	Path    string
+	// Key marks primary and foreign key columns, which need indexable types
+	Key bool
+}
+
+// relMapping maps a document path onto a table and column
+type relMapping struct {
+	Path     string
+	Table    string
+	Column   string
+	Kind     string
+	Nullable bool
+	Strategy string
+}
+
+// relationalBuilder flattens a collection's field tree into tables.
+// Scalars become columns, small embedded objects are flattened into
+// parent_child columns, larger ones move to a one-to-one child table and
+// arrays become child tables with a foreign key to the parent row.
+type relationalBuilder struct {
+	requiredThreshold float64
+	tables            []*relTable
+	mappings          []relMapping
+}
+
+// buildRelational builds the tables and mapping of a collection
+func buildRelational(coll types.Collection, requiredThreshold float64) ([]*relTable, []relMapping) {
+	b := &relationalBuilder{requiredThreshold: requiredThreshold}
+
+	root := b.addTable(sqlIdentifier(coll.Name, "collection"), "")
+	idKind := "objectId"
+	if id := types.FindField(coll.Fields, "_id"); id != nil {
+		idKind = keyKind(*id)
+	}
+	root.PrimaryKey = "id"
+	root.Columns = append(root.Columns, relColumn{Name: "id", Kind: idKind, NotNull: true, Path: "_id", Key: true})
+	b.mappings = append(b.mappings, relMapping{Path: "_id", Table: root.Name, Column: "id", Kind: idKind, Strategy: strategyKey})
+
+	var fields []types.Field
+	for _, f := range coll.Fields {
+		if f.Path != "_id" {
+			fields = append(fields, f)
+		}
+	}
+	b.addColumns(root, fields, nil, "", "", true)
+
+	return b.tables, b.mappings
+}
+
+// addTable appends a new table
+func (b *relationalBuilder) addTable(name, path string) *relTable {
+	t := &relTable{Name: name, Path: path}
+	b.tables = append(b.tables, t)
+	return t
+}
+
+// addColumns maps the fields of object, or the top-level and array element
+// fields when object is nil, onto columns of t, prefixing column names and
+// paths for flattened objects. Columns are only NOT NULL when every
+// enclosing object is required too.
+func (b *relationalBuilder) addColumns(t *relTable, fields []types.Field, object *types.Field, columnPrefix, pathPrefix string, parentRequired bool) {
+	for _, f := range fields {
+		path := joinPath(pathPrefix, f.Path, ".")
+		column := joinPath(columnPrefix, sqlIdentifier(f.Path, "field"), "_")
+		required := parentRequired && schema.Required(f, object, b.requiredThreshold) && !schema.Nullable(f)
+
+		kind := columnKind(f)
+		switch {
+		case kind == "object":
+			if countColumns(f.NestedFields) <= maxFlattenedColumns {
+				b.mappings = append(b.mappings, relMapping{Path: path, Table: t.Name, Column: column + "_*", Strategy: strategyFlattened, Nullable: !required})
+				b.addColumns(t, f.NestedFields, &f, column, path, required)
+			} else {
+				b.addObjectTable(t, f, column, path)
+			}
+		case kind == "array":
+			b.addArrayTable(t, f, column, path)
+		default:
+			b.addColumn(t, relColumn{Name: column, Kind: kind, NotNull: required, Enum: f.Enum, Path: path})
+		}
+	}
+}
+
+// addColumn appends a column with a unique name and records its mapping
+func (b *relationalBuilder) addColumn(t *relTable, c relColumn) {
+	c.Name = uniqueColumn(t, c.Name)
+	t.Columns = append(t.Columns, c)
+
+	strategy := strategyColumn
+	if c.Kind == kindJSON {
+		strategy = strategyJSON
+	}
+	b.mappings = append(b.mappings, relMapping{Path: c.Path, Table: t.Name, Column: c.Name, Kind: c.Kind, Nullable: !c.NotNull, Strategy: strategy})
+}
+
+// addObjectTable moves a large embedded object into a one-to-one child
+// table keyed by the parent's primary key
+func (b *relationalBuilder) addObjectTable(parent *relTable, f types.Field, column, path string) {
+	child := b.addTable(parent.Name+"_"+column, path)
+	fk := b.addForeignKey(parent, child)
+	child.PrimaryKey = fk
+
+	b.mappings = append(b.mappings, relMapping{Path: path, Table: child.Name, Strategy: strategyChildTable, Nullable: true})
+	b.addColumns(child, f.NestedFields, &f, "", path, true)
+}
+
+// addArrayTable moves an array into a child table with one row per element,
+// keyed by a serial id and ordered by position
+func (b *relationalBuilder) addArrayTable(parent *relTable, f types.Field, column, path string) {
+	path += "[]"
+	child := b.addTable(parent.Name+"_"+column, path)
+	child.PrimaryKey = "id"
+	child.Columns = append(child.Columns, relColumn{Name: "id", Kind: kindSerial, NotNull: true, Key: true})
+	b.addForeignKey(parent, child)
+	child.Columns = append(child.Columns, relColumn{Name: "position", Kind: kindPosition, NotNull: true})
+
+	b.mappings = append(b.mappings, relMapping{Path: path, Table: child.Name, Column: "position", Kind: kindPosition, Strategy: strategyChildTable})
+
+	if f.ArrayItems == nil {
+		b.addColumn(child, relColumn{Name: "value", Kind: kindJSON, Path: path})
+		return
+	}
+
+	items := *f.ArrayItems
+	switch kind := columnKind(items); kind {
+	case "object":
+		b.addColumns(child, items.NestedFields, nil, "", path, true)
+	case "array":
+		b.addArrayTable(child, items, "value", path)
+	default:
+		b.addColumn(child, relColumn{Name: "value", Kind: kind, NotNull: !schema.Nullable(items), Enum: items.Enum, Path: path})
+	}
+}
+
+// addForeignKey adds a column referencing the parent's primary key and
+// returns its name
+func (b *relationalBuilder) addForeignKey(parent, child *relTable) string {
+	var parentKey relColumn
+	for _, c := range parent.Columns {
+		if c.Name == parent.PrimaryKey {
+			parentKey = c
+		}
+	}
+	if parentKey.Kind == kindSerial {
+		parentKey.Kind = "int64"
+	}
+
+	name := uniqueColumn(child, naming.Snake(naming.Singular(parent.Name))+"_id")
+	child.Columns = append(child.Columns, relColumn{Name: name, Kind: parentKey.Kind, NotNull: true, Key: true})
+	child.ForeignKey = &relForeignKey{Column: name, ParentTable: parent.Name, ParentKey: parent.PrimaryKey}
+	return name
+}
+
+// columnKind returns the kind of value a field stores. Purely numeric
+// mixes widen to int64 or double; other mixes and unknown types use json.
+func columnKind(f types.Field) string {
+	bsonTypes := schema.Types(f)
+	switch len(bsonTypes) {
+	case 0:
+		return kindJSON
+	case 1:
+		if bsonTypes[0] == "object" && len(f.NestedFields) == 0 {
+			return kindJSON
+		}
+		return bsonTypes[0]
+	}
+
+	kind := "int64"
+	for _, t := range bsonTypes {
+		switch t {
+		case "int32", "int64":
+		case "double":
+			kind = "double"
+		default:
+			return kindJSON
+		}
+	}
+	return kind
+}
+
+// keyKind returns the kind of an _id field, using string for mixed ids
+func keyKind(f types.Field) string {
+	switch kind := columnKind(f); kind {
+	case "object", "array", kindJSON:
+		return "string"
+	default:
+		return kind
+	}
+}
+
+// countColumns counts the columns a field list flattens into
+func countColumns(fields []types.Field) int {
+	count := 0
+	for _, f := range fields {
+		if columnKind(f) == "object" {
+			count += countColumns(f.NestedFields)
+		} else {
+			count++
+		}
+	}
+	return count
+}
+
+// uniqueColumn returns name, or name with a numeric suffix when a column of
+// t already uses it
+func uniqueColumn(t *relTable, name string) string {
+	taken := func(candidate string) bool {
+		return slices.ContainsFunc(t.Columns, func(c relColumn) bool { return c.Name == candidate })
+	}
+
+	candidate := name
+	for i := 2; taken(candidate); i++ {
+		candidate = fmt.Sprintf("%s_%d", name, i)
+	}
+	return candidate
+}
+
+// sqlIdentifier converts a key into a snake_case identifier
+func sqlIdentifier(key, fallback string) string {
+	name := naming.Snake(key)
+	switch {
+	case name == "":
+		return fallback
+	case name[0] >= '0' && name[0] <= '9':
+		return "_" + name
+	default:
+		return name
+	}
+}
+
+// joinPath joins a prefix and a name with sep, omitting an empty prefix
+func joinPath(prefix, name, sep string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + sep + name
+}
@@ -0,0 +1,69 @@
+package exporter
+
+import (
+	"strings"
+
+	"mongo-scanner/internal/analyzer"
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// relationship is an inferred reference field linked to the type of the
+// collection it points to
+type relationship struct {
+	// Path is the dotted path of the reference field
+	Path string
+	// Name is the name of the link field, such as customer for customerId
+	Name string
+	// Target is the type name of the referenced collection
+	Target string
+	Many   bool
+}
+
+// collectionTypeNames assigns a unique PascalCase type name to every
+// collection, keyed by "<db>.<collection>"
+func collectionTypeNames(result *types.ScanResult) map[string]string {
+	taken := make(map[string]bool)
+	names := make(map[string]string)
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			name := avroName(naming.Pascal(naming.Singular(coll.Name)), "Document")
+			names[db.Name+"."+coll.Name] = schema.UniqueName(name, taken)
+		}
+	}
+	return names
+}
+
+// relationships returns the inferred references of a database that resolve
+// to a collection, grouped by collection name
+func relationships(db types.Database, typeNames map[string]string) map[string][]relationship {
+	byCollection := make(map[string][]relationship)
+	for _, ref := range analyzer.InferReferences(db) {
+		target, ok := typeNames[db.Name+"."+ref.TargetCollection]
+		if ref.TargetCollection == "" || !ok {
+			continue
+		}
+
+		byCollection[ref.Collection] = append(byCollection[ref.Collection], relationship{
+			Path:   ref.Path,
+			Name:   relationshipName(ref),
+			Target: target,
+			Many:   ref.Many,
+		})
+	}
+	return byCollection
+}
+
+// relationshipName derives the link field name of a reference: the key
+// without its id suffix for single references (customerId → customer) and
+// the camelCase target collection for many (order_ids → orders)
+func relationshipName(ref types.Reference) string {
+	if ref.Many {
+		return naming.Camel(ref.TargetCollection)
+	}
+
+	key := ref.Path[strings.LastIndex(ref.Path, ".")+1:]
+	words := naming.Words(key)
+	return naming.Camel(strings.Join(words[:len(words)-1], "_"))
+}
@@ -0,0 +1,254 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"mongo-scanner/internal/output"
+	"mongo-scanner/internal/types"
+)
+
+// SQLExporter exports a relational design of each collection as CREATE
+// TABLE statements for PostgreSQL or MySQL. Databases become schemas.
+type SQLExporter struct {
+	Dialect           Format
+	RequiredThreshold float64
+	Split             bool
+}
+
+// Export writes the DDL of every collection
+func (e *SQLExporter) Export(result *types.ScanResult, w io.Writer) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "-- Relational design of %s generated by mongo-scanner (%s)\n", result.ClusterName, e.Dialect)
+
+	for _, db := range result.Databases {
+		fmt.Fprintf(&b, "\n%s\n", e.createSchema(db.Name))
+		for _, coll := range db.Collections {
+			e.writeCollection(&b, db, coll)
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// ExportToFile writes the DDL to a file and the mapping report from
+// document paths to columns next to it as <name>.mapping.csv, compressed
+// like the DDL. With Split, path is a directory receiving one .sql file per
+// collection and a mapping.csv. The mapping is skipped when the DDL is not
+// written to a local file.
+func (e *SQLExporter) ExportToFile(result *types.ScanResult, path string) error {
+	if !output.IsLocal(path) {
+		return writeFile(path, func(w io.Writer) error {
+			return e.Export(result, w)
+		})
+	}
+
+	compression := output.CompressionExt(path)
+	base := strings.TrimSuffix(path, compression)
+	mappingPath := strings.TrimSuffix(base, filepath.Ext(base)) + ".mapping.csv" + compression
+
+	if e.Split {
+		err := writeSplitFiles(result, path, ".sql", func(db types.Database, coll types.Collection, w io.Writer) error {
+			var b bytes.Buffer
+			fmt.Fprintf(&b, "%s\n", e.createSchema(db.Name))
+			e.writeCollection(&b, db, coll)
+			_, err := w.Write(b.Bytes())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		mappingPath = filepath.Join(path, "mapping.csv")
+	} else {
+		err := writeFile(path, func(w io.Writer) error {
+			return e.Export(result, w)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeFile(mappingPath, func(w io.Writer) error {
+		return e.ExportMapping(result, w)
+	})
+}
+
+// ExportMapping writes the mapping report from document paths to tables
+// and columns as CSV
+func (e *SQLExporter) ExportMapping(result *types.ScanResult, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"Database", "Collection", "Document Path", "Table", "Column", "SQL Type", "Nullable", "Strategy"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, db := range result.Databases {
+		schema := sqlIdentifier(db.Name, "db")
+		for _, coll := range db.Collections {
+			_, mappings := buildRelational(coll, e.RequiredThreshold)
+			for _, m := range mappings {
+				sqlType := ""
+				if m.Kind != "" {
+					sqlType = e.sqlType(m.Kind, m.Strategy == strategyKey)
+				}
+				row := []string{
+					db.Name,
+					coll.Name,
+					m.Path,
+					schema + "." + m.Table,
+					m.Column,
+					sqlType,
+					fmt.Sprintf("%t", m.Nullable),
+					m.Strategy,
+				}
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeCollection renders the CREATE TABLE statements of a collection,
+// parent tables first
+func (e *SQLExporter) writeCollection(b *bytes.Buffer, db types.Database, coll types.Collection) {
+	schema := sqlIdentifier(db.Name, "db")
+	tables, _ := buildRelational(coll, e.RequiredThreshold)
+
+	for _, t := range tables {
+		source := coll.Name
+		if t.Path != "" {
+			source += "." + t.Path
+		}
+		fmt.Fprintf(b, "\n-- %s.%s\n", db.Name, source)
+		fmt.Fprintf(b, "CREATE TABLE %s (\n", e.tableName(schema, t.Name))
+
+		for _, c := range t.Columns {
+			line := "  " + e.quote(c.Name) + " " + e.sqlType(c.Kind, c.Key)
+			if c.NotNull {
+				line += " NOT NULL"
+			}
+			if c.Kind == kindSerial && e.Dialect == FormatMySQL {
+				line += " AUTO_INCREMENT"
+			}
+			if len(c.Enum) > 0 {
+				values := make([]string, len(c.Enum))
+				for i, v := range c.Enum {
+					values[i] = sqlString(v)
+				}
+				line += fmt.Sprintf(" CHECK (%s IN (%s))", e.quote(c.Name), strings.Join(values, ", "))
+			}
+			line += ","
+			if c.Path != "" {
+				line += " -- " + c.Path
+			}
+			fmt.Fprintln(b, line)
+		}
+
+		constraints := []string{fmt.Sprintf("  PRIMARY KEY (%s)", e.quote(t.PrimaryKey))}
+		if fk := t.ForeignKey; fk != nil {
+			constraints = append(constraints, fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE CASCADE",
+				e.quote(fk.Column), e.tableName(schema, fk.ParentTable), e.quote(fk.ParentKey)))
+		}
+		fmt.Fprintln(b, strings.Join(constraints, ",\n"))
+
+		if e.Dialect == FormatMySQL {
+			fmt.Fprintln(b, ") ENGINE=InnoDB;")
+		} else {
+			fmt.Fprintln(b, ");")
+		}
+	}
+}
+
+// createSchema returns the statement creating the schema of a database
+func (e *SQLExporter) createSchema(dbName string) string {
+	schema := e.quote(sqlIdentifier(dbName, "db"))
+	if e.Dialect == FormatMySQL {
+		return "CREATE DATABASE IF NOT EXISTS " + schema + ";"
+	}
+	return "CREATE SCHEMA IF NOT EXISTS " + schema + ";"
+}
+
+// tableName returns a schema-qualified, quoted table name
+func (e *SQLExporter) tableName(schema, table string) string {
+	return e.quote(schema) + "." + e.quote(table)
+}
+
+// quote quotes an identifier for the dialect
+func (e *SQLExporter) quote(name string) string {
+	if e.Dialect == FormatMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqlType maps a column kind onto a SQL type. Key columns in MySQL need a
+// bounded length, so strings use VARCHAR there.
+func (e *SQLExporter) sqlType(kind string, key bool) string {
+	if e.Dialect == FormatMySQL {
+		switch kind {
+		case "string", "regex":
+			if key {
+				return "VARCHAR(255)"
+			}
+			return "TEXT"
+		case "int32", kindPosition:
+			return "INT"
+		case "int64", "timestamp", kindSerial:
+			return "BIGINT"
+		case "double":
+			return "DOUBLE"
+		case "decimal":
+			return "DECIMAL(38, 10)"
+		case "boolean":
+			return "BOOLEAN"
+		case "date":
+			return "DATETIME(3)"
+		case "objectId":
+			return "CHAR(24)"
+		case "binData":
+			return "LONGBLOB"
+		default:
+			return "JSON"
+		}
+	}
+
+	switch kind {
+	case "string", "regex":
+		return "TEXT"
+	case "int32", kindPosition:
+		return "INTEGER"
+	case "int64", "timestamp":
+		return "BIGINT"
+	case kindSerial:
+		return "BIGSERIAL"
+	case "double":
+		return "DOUBLE PRECISION"
+	case "decimal":
+		return "NUMERIC"
+	case "boolean":
+		return "BOOLEAN"
+	case "date":
+		return "TIMESTAMPTZ"
+	case "objectId":
+		return "CHAR(24)"
+	case "binData":
+		return "BYTEA"
+	default:
+		return "JSONB"
+	}
+}
+
+// sqlString returns a single-quoted SQL string literal
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,139 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"mongo-scanner/internal/analyzer"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// StructurizrExporter exports the cluster as a Structurizr DSL workspace
+// using the C4 levels of the explorer: the cluster is a software system,
+// each database a container and each collection a component. Scan stats
+// are attached as properties and inferred references become relationships
+// between components.
+type StructurizrExporter struct{}
+
+// Export writes the workspace
+func (e *StructurizrExporter) Export(result *types.ScanResult, w io.Writer) error {
+	var b strings.Builder
+
+	collections := 0
+	fields := 0
+	for _, db := range result.Databases {
+		collections += len(db.Collections)
+		for _, coll := range db.Collections {
+			fields += types.CountFields(coll.Fields)
+		}
+	}
+
+	fmt.Fprintf(&b, "workspace %s %s {\n", structurizrString(result.ClusterName), structurizrString("MongoDB schema scanned "+result.ScanTimestamp))
+	b.WriteString("    !identifiers hierarchical\n\n")
+	b.WriteString("    model {\n")
+
+	fmt.Fprintf(&b, "        cluster = softwareSystem %s \"MongoDB cluster\" {\n", structurizrString(result.ClusterName))
+	b.WriteString("            tags \"MongoDB Cluster\"\n")
+	writeStructurizrProperties(&b, "            ", [][2]string{
+		{"databases", fmt.Sprint(len(result.Databases))},
+		{"collections", fmt.Sprint(collections)},
+		{"fields", fmt.Sprint(fields)},
+		{"scan_timestamp", result.ScanTimestamp},
+	})
+
+	dbIDs := make(map[string]bool)
+	containers := make([]string, 0, len(result.Databases))
+	var relationships []string
+	for _, db := range result.Databases {
+		dbID := schema.UniqueName(structurizrIdentifier(db.Name), dbIDs)
+		containers = append(containers, dbID)
+
+		fmt.Fprintf(&b, "\n            %s = container %s \"MongoDB database\" \"MongoDB\" {\n", dbID, structurizrString(db.Name))
+		b.WriteString("                tags \"MongoDB Database\"\n")
+		writeStructurizrProperties(&b, "                ", [][2]string{
+			{"size_bytes", fmt.Sprint(db.SizeBytes)},
+			{"collections", fmt.Sprint(len(db.Collections))},
+		})
+
+		collIDs := make(map[string]bool)
+		components := make(map[string]string, len(db.Collections))
+		for _, coll := range db.Collections {
+			collID := schema.UniqueName(structurizrIdentifier(coll.Name), collIDs)
+			components[coll.Name] = "cluster." + dbID + "." + collID
+
+			fmt.Fprintf(&b, "\n                %s = component %s \"MongoDB collection\" \"Collection\" {\n", collID, structurizrString(coll.Name))
+			b.WriteString("                    tags \"MongoDB Collection\"\n")
+			writeStructurizrProperties(&b, "                    ", [][2]string{
+				{"document_count", fmt.Sprint(coll.DocumentCount)},
+				{"average_doc_size_bytes", fmt.Sprint(coll.AverageDocSizeBytes)},
+				{"fields", fmt.Sprint(types.CountFields(coll.Fields))},
+				{"indexes", fmt.Sprint(len(coll.Indexes))},
+			})
+			b.WriteString("                }\n")
+		}
+		b.WriteString("            }\n")
+
+		for _, ref := range analyzer.InferReferences(db) {
+			target, ok := components[ref.TargetCollection]
+			if ref.TargetCollection == "" || !ok {
+				continue
+			}
+			relationships = append(relationships, fmt.Sprintf("        %s -> %s %s \"Reference\"\n",
+				components[ref.Collection], target, structurizrString("References via "+ref.Path)))
+		}
+	}
+	b.WriteString("        }\n")
+
+	if len(relationships) > 0 {
+		b.WriteString("\n")
+		for _, relationship := range relationships {
+			b.WriteString(relationship)
+		}
+	}
+	b.WriteString("    }\n\n")
+
+	b.WriteString("    views {\n")
+	b.WriteString("        systemContext cluster \"SystemContext\" {\n            include *\n            autoLayout\n        }\n\n")
+	b.WriteString("        container cluster \"Databases\" {\n            include *\n            autoLayout\n        }\n")
+	for _, dbID := range containers {
+		fmt.Fprintf(&b, "\n        component cluster.%s %s {\n            include *\n            autoLayout\n        }\n",
+			dbID, structurizrString("Collections-"+dbID))
+	}
+	b.WriteString("\n        styles {\n")
+	b.WriteString("            element \"MongoDB Database\" {\n                shape cylinder\n            }\n")
+	b.WriteString("            element \"MongoDB Collection\" {\n                shape component\n            }\n")
+	b.WriteString("        }\n")
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ExportToFile writes the workspace to a file
+func (e *StructurizrExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// writeStructurizrProperties writes a properties block
+func writeStructurizrProperties(b *strings.Builder, indent string, properties [][2]string) {
+	fmt.Fprintf(b, "%sproperties {\n", indent)
+	for _, p := range properties {
+		fmt.Fprintf(b, "%s    %s %s\n", indent, structurizrString(p[0]), structurizrString(p[1]))
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// structurizrIdentifier makes a name a valid element identifier
+func structurizrIdentifier(name string) string {
+	return avroName(name, "element")
+}
+
+// structurizrString quotes a string for the DSL
+func structurizrString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s) + `"`
+}
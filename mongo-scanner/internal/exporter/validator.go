@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"io"
+
+	"mongo-scanner/internal/types"
+)
+
+// ValidatorExporter exports collMod commands carrying a MongoDB $jsonSchema
+// validator for each collection
+type ValidatorExporter struct {
+	RequiredThreshold float64
+	Split             bool
+}
+
+// Export writes one collMod command per collection, keyed by "<db>.<collection>"
+func (e *ValidatorExporter) Export(result *types.ScanResult, w io.Writer) error {
+	commands := make(map[string]interface{})
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			commands[db.Name+"."+coll.Name] = e.collMod(coll)
+		}
+	}
+
+	return encodeJSON(w, commands)
+}
+
+// ExportToFile writes all commands to a file, or one command per collection
+// into the directory at filepath when Split is set
+func (e *ValidatorExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	if e.Split {
+		return writeSplitFiles(result, filepath, ".validator.json", func(db types.Database, coll types.Collection, w io.Writer) error {
+			return encodeJSON(w, e.collMod(coll))
+		})
+	}
+
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// collMod wraps a collection's validator in a collMod command
+func (e *ValidatorExporter) collMod(coll types.Collection) map[string]interface{} {
+	return map[string]interface{}{
+		"collMod":   coll.Name,
+		"validator": BuildValidator(coll, e.RequiredThreshold),
+	}
+}
+
+// BuildValidator builds the {"$jsonSchema": ...} validator document of a collection
+func BuildValidator(coll types.Collection, requiredThreshold float64) map[string]interface{} {
+	builder := schemaBuilder{requiredThreshold: requiredThreshold, bsonTypes: true}
+
+	schema := map[string]interface{}{
+		"bsonType": "object",
+		"title":    coll.Name,
+	}
+	builder.addProperties(schema, coll.Fields, nil)
+
+	return map[string]interface{}{
+		"$jsonSchema": schema,
+	}
+}
+
+// mongoBSONType maps a type name from types.GetBSONTypeName to the
+// corresponding $jsonSchema bsonType alias
+func mongoBSONType(bsonType string) string {
+	switch bsonType {
+	case "int32", "int":
+		return "int"
+	case "int64", "long":
+		return "long"
+	case "boolean", "bool":
+		return "bool"
+	case "string", "double", "decimal", "objectId", "date", "array", "object",
+		"binData", "regex", "timestamp", "null":
+		return bsonType
+	default:
+		return ""
+	}
+}
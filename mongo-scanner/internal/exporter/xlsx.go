@@ -0,0 +1,388 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// Spreadsheet limits of the Office Open XML format
+const (
+	xlsxMaxSheetName    = 31
+	xlsxMaxOutlineLevel = 7
+	xlsxMaxIndent       = 15
+)
+
+// Cell styles defined in xlsxStyles, by index into cellXfs
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleHeader  = 1
+	xlsxStylePercent = 2
+	xlsxStyleLink    = 3
+	// xlsxStyleIndent is the first of the indented path styles, one per
+	// indent level starting at 1
+	xlsxStyleIndent = 4
+)
+
+// xlsxSheetNameReplacer replaces characters Excel rejects in sheet names
+var xlsxSheetNameReplacer = strings.NewReplacer(
+	"[", "(", "]", ")", ":", "_", "*", "_", "?", "_", "/", "_", "\\", "_",
+)
+
+// XLSXExporter exports scan results as an Excel workbook with a summary
+// sheet and one sheet per collection listing its fields. The workbook is
+// written as plain Office Open XML parts, with no external dependencies.
+type XLSXExporter struct{}
+
+// xlsxCell is a cell value: a string, int64 or float64
+type xlsxCell struct {
+	Value interface{}
+	Style int
+	// Link is the sheet an internal hyperlink points to
+	Link string
+}
+
+// xlsxSheet is a worksheet
+type xlsxSheet struct {
+	Name    string
+	Widths  []float64
+	Rows    [][]xlsxCell
+	Outline []int
+	// PercentColumn is the zero-based column that gets a color scale,
+	// or -1
+	PercentColumn int
+}
+
+// Export writes the workbook
+func (e *XLSXExporter) Export(result *types.ScanResult, w io.Writer) error {
+	sheetNames := make(map[string]bool)
+	summary := &xlsxSheet{
+		Name:          uniqueSheetName("Summary", sheetNames),
+		Widths:        []float64{24, 30, 16, 20, 18, 10, 10, 30},
+		PercentColumn: -1,
+	}
+	summary.Rows = append(summary.Rows, xlsxHeader(
+		"Database", "Collection", "Document Count", "Avg Doc Size (bytes)", "DB Size (bytes)", "Fields", "Indexes", "Sheet",
+	))
+
+	sheets := []*xlsxSheet{summary}
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			sheet := &xlsxSheet{
+				Name:          uniqueSheetName(db.Name+"."+coll.Name, sheetNames),
+				Widths:        []float64{40, 14, 12, 40},
+				PercentColumn: 2,
+			}
+			sheet.Rows = append(sheet.Rows, xlsxHeader("Field Path", "Inferred Type", "Presence %", "Type Distribution"))
+			sheet.Outline = append(sheet.Outline, 0)
+
+			for _, row := range schema.Flatten(coll.Fields) {
+				pathStyle := xlsxStyleDefault
+				if row.Depth > 0 {
+					pathStyle = xlsxStyleIndent + min(row.Depth, xlsxMaxIndent) - 1
+				}
+				sheet.Rows = append(sheet.Rows, []xlsxCell{
+					{Value: row.Path, Style: pathStyle},
+					{Value: row.Field.InferredType},
+					{Value: row.Field.PresencePercent, Style: xlsxStylePercent},
+					{Value: schema.TypeDistribution(row.Field.Types)},
+				})
+				sheet.Outline = append(sheet.Outline, min(row.Depth, xlsxMaxOutlineLevel))
+			}
+			sheets = append(sheets, sheet)
+
+			summary.Rows = append(summary.Rows, []xlsxCell{
+				{Value: db.Name},
+				{Value: coll.Name},
+				{Value: coll.DocumentCount},
+				{Value: coll.AverageDocSizeBytes},
+				{Value: db.SizeBytes},
+				{Value: int64(types.CountFields(coll.Fields))},
+				{Value: int64(len(coll.Indexes))},
+				{Value: sheet.Name, Style: xlsxStyleLink, Link: sheet.Name},
+			})
+		}
+	}
+
+	return writeXLSX(w, sheets)
+}
+
+// ExportToFile writes the workbook to a file
+func (e *XLSXExporter) ExportToFile(result *types.ScanResult, filepath string) error {
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
+}
+
+// xlsxHeader builds a header row
+func xlsxHeader(titles ...string) []xlsxCell {
+	cells := make([]xlsxCell, len(titles))
+	for i, title := range titles {
+		cells[i] = xlsxCell{Value: title, Style: xlsxStyleHeader}
+	}
+	return cells
+}
+
+// uniqueSheetName makes a name a valid sheet name that differs, ignoring
+// case, from the names already taken
+func uniqueSheetName(name string, taken map[string]bool) string {
+	name = strings.Trim(xlsxSheetNameReplacer.Replace(name), "'")
+	if name == "" {
+		name = "Sheet"
+	}
+
+	candidate := truncateRunes(name, xlsxMaxSheetName)
+	for i := 2; taken[strings.ToLower(candidate)]; i++ {
+		suffix := fmt.Sprintf("~%d", i)
+		candidate = truncateRunes(name, xlsxMaxSheetName-len(suffix)) + suffix
+	}
+	taken[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// truncateRunes shortens s to at most n runes
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// writeXLSX writes the package parts of a workbook
+func writeXLSX(w io.Writer, sheets []*xlsxSheet) error {
+	z := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", xlsxContentTypes(len(sheets))},
+		{"_rels/.rels", []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`)},
+		{"xl/workbook.xml", xlsxWorkbook(sheets)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels(len(sheets))},
+		{"xl/styles.xml", xlsxStyles()},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct {
+			name    string
+			content []byte
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.xml()})
+	}
+
+	for _, part := range parts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(part.content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// xlsxContentTypes renders [Content_Types].xml
+func xlsxContentTypes(sheets int) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.Bytes()
+}
+
+// xlsxWorkbook renders xl/workbook.xml
+func xlsxWorkbook(sheets []*xlsxSheet) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.Name), i+1, i+1)
+	}
+	b.WriteString(`</sheets>`)
+
+	// Excel expects the range of each autofilter as a hidden defined name
+	b.WriteString(`<definedNames>`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&b, `<definedName name="_xlnm._FilterDatabase" localSheetId="%d" hidden="1">%s!$A$1:%s</definedName>`,
+			i, xmlEscape(xlsxQuoteSheet(sheet.Name)), xlsxAbsolute(sheet.lastCell()))
+	}
+	b.WriteString(`</definedNames></workbook>`)
+	return b.Bytes()
+}
+
+// xlsxWorkbookRels renders xl/_rels/workbook.xml.rels. Sheets use rId1 to
+// rIdN and the styles part the next id.
+func xlsxWorkbookRels(sheets int) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString(`</Relationships>`)
+	return b.Bytes()
+}
+
+// xlsxStyles renders xl/styles.xml with the cell styles of the xlsxStyle
+// constants
+func xlsxStyles() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<numFmts count="1"><numFmt numFmtId="164" formatCode="0.0&quot;%&quot;"/></numFmts>`)
+	b.WriteString(`<fonts count="3">`)
+	b.WriteString(`<font><sz val="11"/><name val="Calibri"/></font>`)
+	b.WriteString(`<font><b/><sz val="11"/><name val="Calibri"/></font>`)
+	b.WriteString(`<font><u/><sz val="11"/><color rgb="FF0563C1"/><name val="Calibri"/></font>`)
+	b.WriteString(`</fonts>`)
+	b.WriteString(`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>`)
+	b.WriteString(`<fill><patternFill patternType="solid"><fgColor rgb="FFD9E1F2"/><bgColor indexed="64"/></patternFill></fill></fills>`)
+	b.WriteString(`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>`)
+	b.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+
+	fmt.Fprintf(&b, `<cellXfs count="%d">`, xlsxStyleIndent+xlsxMaxIndent)
+	b.WriteString(`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>`)
+	b.WriteString(`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>`)
+	b.WriteString(`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>`)
+	b.WriteString(`<xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1"/>`)
+	for indent := 1; indent <= xlsxMaxIndent; indent++ {
+		fmt.Fprintf(&b, `<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0" applyAlignment="1"><alignment indent="%d"/></xf>`, indent)
+	}
+	b.WriteString(`</cellXfs>`)
+
+	b.WriteString(`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>`)
+	b.WriteString(`</styleSheet>`)
+	return b.Bytes()
+}
+
+// xml renders the worksheet part. The header row is frozen and filtered,
+// outline levels group nested fields under their parent and the percent
+// column gets a red-yellow-green color scale.
+func (s *xlsxSheet) xml() []byte {
+	var b bytes.Buffer
+	lastCell := s.lastCell()
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	if len(s.Outline) > 0 {
+		// Summary rows sit above their details, which follow the parent field
+		b.WriteString(`<sheetPr><outlinePr summaryBelow="0"/></sheetPr>`)
+	}
+	fmt.Fprintf(&b, `<dimension ref="A1:%s"/>`, lastCell)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+
+	b.WriteString(`<cols>`)
+	for i, width := range s.Widths {
+		fmt.Fprintf(&b, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, width)
+	}
+	b.WriteString(`</cols>`)
+
+	b.WriteString(`<sheetData>`)
+	var links []string
+	for r, row := range s.Rows {
+		outline := ""
+		if r < len(s.Outline) && s.Outline[r] > 0 {
+			outline = fmt.Sprintf(` outlineLevel="%d"`, s.Outline[r])
+		}
+		fmt.Fprintf(&b, `<row r="%d"%s>`, r+1, outline)
+		for c, cell := range row {
+			ref := xlsxCellRef(c, r+1)
+			style := ""
+			if cell.Style != xlsxStyleDefault {
+				style = fmt.Sprintf(` s="%d"`, cell.Style)
+			}
+
+			switch v := cell.Value.(type) {
+			case int64:
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%d</v></c>`, ref, style, v)
+			case float64:
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%g</v></c>`, ref, style, v)
+			default:
+				fmt.Fprintf(&b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xmlEscape(fmt.Sprint(v)))
+			}
+
+			if cell.Link != "" {
+				links = append(links, fmt.Sprintf(`<hyperlink ref="%s" location="%s" display="%s"/>`,
+					ref, xmlEscape(xlsxQuoteSheet(cell.Link)+"!A1"), xmlEscape(cell.Link)))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+
+	fmt.Fprintf(&b, `<autoFilter ref="A1:%s"/>`, lastCell)
+
+	if s.PercentColumn >= 0 && len(s.Rows) > 1 {
+		column := xlsxColumnName(s.PercentColumn)
+		fmt.Fprintf(&b, `<conditionalFormatting sqref="%s2:%s%d">`, column, column, len(s.Rows))
+		b.WriteString(`<cfRule type="colorScale" priority="1"><colorScale>`)
+		b.WriteString(`<cfvo type="num" val="0"/><cfvo type="num" val="50"/><cfvo type="num" val="100"/>`)
+		b.WriteString(`<color rgb="FFF8696B"/><color rgb="FFFFEB84"/><color rgb="FF63BE7B"/>`)
+		b.WriteString(`</colorScale></cfRule></conditionalFormatting>`)
+	}
+
+	if len(links) > 0 {
+		b.WriteString(`<hyperlinks>` + strings.Join(links, "") + `</hyperlinks>`)
+	}
+	b.WriteString(`</worksheet>`)
+	return b.Bytes()
+}
+
+// lastCell returns the reference of the bottom right cell of the sheet
+func (s *xlsxSheet) lastCell() string {
+	columns := 0
+	for _, row := range s.Rows {
+		columns = max(columns, len(row))
+	}
+	return xlsxCellRef(max(columns-1, 0), max(len(s.Rows), 1))
+}
+
+// xlsxQuoteSheet quotes a sheet name for use in formulas and references
+func xlsxQuoteSheet(name string) string {
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// xlsxAbsolute turns a cell reference such as D10 into $D$10
+func xlsxAbsolute(ref string) string {
+	i := strings.IndexAny(ref, "0123456789")
+	return "$" + ref[:i] + "$" + ref[i:]
+}
+
+// xlsxCellRef returns the A1 reference of a zero-based column and a
+// one-based row
+func xlsxCellRef(column, row int) string {
+	return fmt.Sprintf("%s%d", xlsxColumnName(column), row)
+}
+
+// xlsxColumnName returns the letters of a zero-based column index
+func xlsxColumnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name
+}
+
+// xmlEscape escapes text for XML content and attribute values
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
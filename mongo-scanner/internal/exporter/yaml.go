@@ -1,9 +1,7 @@
 package exporter
 
 import (
-	"fmt"
 	"io"
-	"os"
 
 	"gopkg.in/yaml.v3"
 
@@ -22,11 +20,7 @@ func (e *YAMLExporter) Export(result *types.ScanResult, w io.Writer) error {
 
 // ExportToFile writes the scan result to a YAML file
 func (e *YAMLExporter) ExportToFile(result *types.ScanResult, filepath string) error {
-	f, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filepath, err)
-	}
-	defer f.Close()
-
-	return e.Export(result, f)
+	return writeFile(filepath, func(w io.Writer) error {
+		return e.Export(result, w)
+	})
 }
@@ -0,0 +1,352 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"mongo-scanner/internal/naming"
+	"mongo-scanner/internal/types"
+)
+
+// Lint rule identifiers
+const (
+	RuleInconsistentCasing = "inconsistent-casing"
+	RuleKeyCollision       = "key-collision"
+	RuleInvalidCharacters  = "invalid-characters"
+	RuleLongKey            = "long-key"
+	RuleReservedName       = "reserved-name"
+)
+
+// reservedNames lists keys that clash with language or ODM internals
+var reservedNames = map[string]types.Severity{
+	"__proto__":   types.SeverityError,
+	"constructor": types.SeverityWarning,
+	"prototype":   types.SeverityWarning,
+	"__v":         types.SeverityInfo,
+	"__t":         types.SeverityInfo,
+	"_class":      types.SeverityInfo,
+}
+
+// Options configures the naming lint
+type Options struct {
+	// MaxKeyLength is the key length above which a key is reported as too long
+	MaxKeyLength int
+}
+
+// DefaultOptions returns default lint settings
+func DefaultOptions() Options {
+	return Options{
+		MaxKeyLength: 64,
+	}
+}
+
+// key is a single field name found in a collection
+type key struct {
+	name   string
+	path   string
+	parent string
+}
+
+// collectionStyle records the dominant naming style of a collection
+type collectionStyle struct {
+	name  string
+	style naming.Style
+}
+
+// Lint checks field naming conventions per collection and across the cluster
+func Lint(result *types.ScanResult, opts Options) []types.Finding {
+	if opts.MaxKeyLength <= 0 {
+		opts.MaxKeyLength = DefaultOptions().MaxKeyLength
+	}
+
+	var findings []types.Finding
+	var styles []collectionStyle
+	// normalized key -> spelling -> collections using it
+	spellings := make(map[string]map[string][]string)
+
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			keys := collectKeys(coll.Fields, "")
+			qualified := db.Name + "." + coll.Name
+
+			findings = append(findings, lintKeys(db.Name, coll.Name, keys, opts)...)
+			findings = append(findings, lintCollisions(db.Name, coll.Name, keys)...)
+
+			style, finding := lintCasing(db.Name, coll.Name, keys)
+			if finding != nil {
+				findings = append(findings, *finding)
+			}
+			if style != "" {
+				styles = append(styles, collectionStyle{name: qualified, style: style})
+			}
+
+			for _, k := range keys {
+				normalized := naming.Normalize(k.name)
+				if normalized == "" || k.name == "_id" {
+					continue
+				}
+				if spellings[normalized] == nil {
+					spellings[normalized] = make(map[string][]string)
+				}
+				users := spellings[normalized][k.name]
+				if len(users) == 0 || users[len(users)-1] != qualified {
+					spellings[normalized][k.name] = append(users, qualified)
+				}
+			}
+		}
+	}
+
+	findings = append(findings, lintClusterCasing(styles)...)
+	findings = append(findings, lintClusterSpellings(spellings)...)
+
+	sortFindings(findings)
+	return findings
+}
+
+// collectKeys flattens a field tree into its keys. Keys of array elements
+// use dot notation paths, as in queries and index definitions.
+func collectKeys(fields []types.Field, parent string) []key {
+	var keys []key
+	for _, f := range fields {
+		path := f.Path
+		if parent != "" {
+			path = parent + "." + f.Path
+		}
+		keys = append(keys, key{name: f.Path, path: path, parent: parent})
+		if len(f.NestedFields) > 0 {
+			keys = append(keys, collectKeys(f.NestedFields, path)...)
+		}
+		for items := f.ArrayItems; items != nil; items = items.ArrayItems {
+			keys = append(keys, collectKeys(items.NestedFields, path)...)
+		}
+	}
+	return keys
+}
+
+// lintKeys checks individual keys for invalid characters, length and reserved names
+func lintKeys(dbName, collName string, keys []key, opts Options) []types.Finding {
+	var findings []types.Finding
+
+	for _, k := range keys {
+		newFinding := func(rule string, severity types.Severity, message, suggestion string) types.Finding {
+			return types.Finding{
+				Rule:       rule,
+				Severity:   severity,
+				Database:   dbName,
+				Collection: collName,
+				Path:       k.path,
+				Message:    message,
+				Suggestion: suggestion,
+			}
+		}
+
+		switch {
+		case k.name == "":
+			findings = append(findings, newFinding(RuleInvalidCharacters, types.SeverityError,
+				"empty key", "rename the field"))
+		case strings.HasPrefix(k.name, "$"):
+			findings = append(findings, newFinding(RuleInvalidCharacters, types.SeverityError,
+				fmt.Sprintf("key %q starts with $ and is ambiguous with query operators", k.name),
+				"rename the field without the $ prefix"))
+		case strings.ContainsAny(k.name, ".$"):
+			findings = append(findings, newFinding(RuleInvalidCharacters, types.SeverityError,
+				fmt.Sprintf("key %q contains . or $ and cannot be addressed with dot notation", k.name),
+				"replace . and $ with _"))
+		case strings.TrimSpace(k.name) != k.name:
+			findings = append(findings, newFinding(RuleInvalidCharacters, types.SeverityError,
+				fmt.Sprintf("key %q has leading or trailing whitespace", k.name),
+				fmt.Sprintf("rename to %q", strings.TrimSpace(k.name))))
+		case strings.IndexFunc(k.name, unicode.IsSpace) >= 0:
+			findings = append(findings, newFinding(RuleInvalidCharacters, types.SeverityWarning,
+				fmt.Sprintf("key %q contains whitespace", k.name), ""))
+		}
+
+		if len(k.name) > opts.MaxKeyLength {
+			findings = append(findings, newFinding(RuleLongKey, types.SeverityWarning,
+				fmt.Sprintf("key is %d characters long (max %d); keys are stored in every document", len(k.name), opts.MaxKeyLength),
+				"use a shorter key"))
+		}
+
+		if severity, ok := reservedNames[k.name]; ok {
+			findings = append(findings, newFinding(RuleReservedName, severity,
+				fmt.Sprintf("key %q is reserved by JavaScript or an ODM", k.name), ""))
+		} else if k.name == "_id" && k.parent != "" {
+			findings = append(findings, newFinding(RuleReservedName, types.SeverityInfo,
+				"embedded document uses the reserved name _id", ""))
+		} else if strings.HasPrefix(k.name, "_") && k.name != "_id" {
+			findings = append(findings, newFinding(RuleReservedName, types.SeverityInfo,
+				fmt.Sprintf("key %q has a leading underscore, which usually denotes internal fields", k.name), ""))
+		}
+	}
+
+	return findings
+}
+
+// lintCollisions reports sibling keys that differ only by case or separator
+func lintCollisions(dbName, collName string, keys []key) []types.Finding {
+	groups := make(map[string][]string)
+	var order []string
+
+	for _, k := range keys {
+		normalized := k.parent + "\x00" + naming.Normalize(k.name)
+		paths, ok := groups[normalized]
+		if !ok {
+			order = append(order, normalized)
+		}
+		// Objects and array elements at the same path share keys
+		if !slices.Contains(paths, k.path) {
+			groups[normalized] = append(paths, k.path)
+		}
+	}
+
+	var findings []types.Finding
+	for _, normalized := range order {
+		paths := groups[normalized]
+		if len(paths) < 2 {
+			continue
+		}
+		sort.Strings(paths)
+		findings = append(findings, types.Finding{
+			Rule:       RuleKeyCollision,
+			Severity:   types.SeverityError,
+			Database:   dbName,
+			Collection: collName,
+			Path:       paths[0],
+			Message:    fmt.Sprintf("keys differ only by case or separator: %s", strings.Join(paths, ", ")),
+			Suggestion: "migrate documents to a single spelling",
+		})
+	}
+
+	return findings
+}
+
+// lintCasing reports collections mixing naming styles and returns the dominant style
+func lintCasing(dbName, collName string, keys []key) (naming.Style, *types.Finding) {
+	counts := make(map[naming.Style]int)
+	for _, k := range keys {
+		style := naming.DetectStyle(k.name)
+		if naming.IsMultiWord(style) {
+			counts[style]++
+		}
+	}
+
+	ranked := rankStyles(counts)
+	if len(ranked) == 0 {
+		return "", nil
+	}
+	if len(ranked) == 1 {
+		return ranked[0], nil
+	}
+
+	return ranked[0], &types.Finding{
+		Rule:       RuleInconsistentCasing,
+		Severity:   types.SeverityWarning,
+		Database:   dbName,
+		Collection: collName,
+		Message:    fmt.Sprintf("collection mixes naming styles: %s", describeStyles(ranked, counts)),
+		Suggestion: fmt.Sprintf("use %s consistently", ranked[0]),
+	}
+}
+
+// lintClusterCasing reports collections whose dominant style differs from the cluster's
+func lintClusterCasing(styles []collectionStyle) []types.Finding {
+	counts := make(map[naming.Style]int)
+	for _, cs := range styles {
+		counts[cs.style]++
+	}
+
+	ranked := rankStyles(counts)
+	if len(ranked) < 2 {
+		return nil
+	}
+
+	var deviating []string
+	for _, cs := range styles {
+		if cs.style != ranked[0] {
+			deviating = append(deviating, fmt.Sprintf("%s (%s)", cs.name, cs.style))
+		}
+	}
+	sort.Strings(deviating)
+
+	return []types.Finding{{
+		Rule:       RuleInconsistentCasing,
+		Severity:   types.SeverityInfo,
+		Message:    fmt.Sprintf("collections use different naming styles: %s", describeStyles(ranked, counts)),
+		Suggestion: fmt.Sprintf("cluster convention is %s; deviating: %s", ranked[0], strings.Join(deviating, ", ")),
+	}}
+}
+
+// lintClusterSpellings reports the same logical key spelled differently across collections
+func lintClusterSpellings(spellings map[string]map[string][]string) []types.Finding {
+	var findings []types.Finding
+
+	for _, variants := range spellings {
+		if len(variants) < 2 {
+			continue
+		}
+
+		names := make([]string, 0, len(variants))
+		for name := range variants {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("%s (%s)", name, strings.Join(variants[name], ", ")))
+		}
+
+		findings = append(findings, types.Finding{
+			Rule:     RuleKeyCollision,
+			Severity: types.SeverityWarning,
+			Path:     names[0],
+			Message:  fmt.Sprintf("same key spelled differently across collections: %s", strings.Join(parts, "; ")),
+		})
+	}
+
+	return findings
+}
+
+// rankStyles orders styles by usage count (descending), then name
+func rankStyles(counts map[naming.Style]int) []naming.Style {
+	ranked := make([]naming.Style, 0, len(counts))
+	for style := range counts {
+		ranked = append(ranked, style)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if counts[ranked[i]] != counts[ranked[j]] {
+			return counts[ranked[i]] > counts[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	return ranked
+}
+
+// describeStyles renders style usage counts, e.g. "camelCase (12), snake_case (3)"
+func describeStyles(ranked []naming.Style, counts map[naming.Style]int) string {
+	parts := make([]string, 0, len(ranked))
+	for _, style := range ranked {
+		parts = append(parts, fmt.Sprintf("%s (%d)", style, counts[style]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortFindings orders findings with cluster-wide entries first, then by location and rule
+func sortFindings(findings []types.Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		if a.Collection != b.Collection {
+			return a.Collection < b.Collection
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Rule < b.Rule
+	})
+}
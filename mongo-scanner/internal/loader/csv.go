@@ -0,0 +1,222 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// csvPathHeader is the header of the field path column, present in every
+// CSV export that can be loaded
+const csvPathHeader = "Field Path"
+
+// csvNode is a field being rebuilt from CSV rows
+type csvNode struct {
+	field    types.Field
+	children []*csvNode
+	items    *csvNode
+	// longTypes is set once a long format row added an observed type
+	longTypes bool
+}
+
+// csvCollection is a collection being rebuilt from CSV rows
+type csvCollection struct {
+	collection types.Collection
+	fields     []*csvNode
+	nodes      map[string]*csvNode
+}
+
+// loadCSV rebuilds a scan result from a CSV export, in the wide or long
+// layout and with any delimiter. Only the exported columns are restored:
+// database sizes, index definitions, drift and statistics are lost. Index
+// names come from the Indexes column, which misses indexes on fields without
+// a row, so the collections are marked as having unknown indexes.
+func loadCSV(data []byte) (*types.ScanResult, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = sniffDelimiter(data)
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty CSV file")
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		columns[strings.TrimSpace(header)] = i
+	}
+	for _, required := range []string{"Database", "Collection", csvPathHeader} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV file has no %q column", required)
+		}
+	}
+
+	var dbNames []string
+	collections := make(map[string][]*csvCollection)
+	byName := make(map[string]*csvCollection)
+
+	for line, record := range records[1:] {
+		value := func(header string) string {
+			if i, ok := columns[header]; ok {
+				return record[i]
+			}
+			return ""
+		}
+		number := func(header string) (float64, error) {
+			s := value(header)
+			if s == "" {
+				return 0, nil
+			}
+			n, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return 0, fmt.Errorf("line %d: invalid %s %q", line+2, header, s)
+			}
+			return n, nil
+		}
+
+		dbName, collName := value("Database"), value("Collection")
+		key := dbName + "." + collName
+		coll, ok := byName[key]
+		if !ok {
+			count, err := number("Document Count")
+			if err != nil {
+				return nil, err
+			}
+			avgSize, err := number("Avg Doc Size (bytes)")
+			if err != nil {
+				return nil, err
+			}
+
+			coll = &csvCollection{
+				collection: types.Collection{
+					Name:                collName,
+					DocumentCount:       int64(count),
+					AverageDocSizeBytes: int64(avgSize),
+					Indexes:             []string{},
+					IndexesUnknown:      true,
+				},
+				nodes: make(map[string]*csvNode),
+			}
+			byName[key] = coll
+			if _, ok := collections[dbName]; !ok {
+				dbNames = append(dbNames, dbName)
+			}
+			collections[dbName] = append(collections[dbName], coll)
+		}
+
+		for _, index := range strings.Split(value("Indexes"), "|") {
+			if index != "" && !slices.Contains(coll.collection.Indexes, index) {
+				coll.collection.Indexes = append(coll.collection.Indexes, index)
+			}
+		}
+
+		path := value(csvPathHeader)
+		node, ok := coll.nodes[path]
+		if !ok {
+			presence, err := number("Presence %")
+			if err != nil {
+				return nil, err
+			}
+			node = &csvNode{field: types.Field{
+				InferredType:    value("Inferred Type"),
+				PresencePercent: presence,
+			}}
+			if enum := value("Enum Values"); enum != "" {
+				node.field.Enum = strings.Split(enum, "|")
+			}
+			if distribution := value("Type Distribution"); distribution != "" {
+				if node.field.Types, err = schema.ParseTypeDistribution(distribution); err != nil {
+					return nil, fmt.Errorf("line %d: %w", line+2, err)
+				}
+			}
+			coll.add(path, node)
+		}
+
+		// Long format rows carry one observed type each and replace the
+		// type distribution when both columns were exported
+		if typeName := value("Type"); typeName != "" {
+			percent, err := number("Type %")
+			if err != nil {
+				return nil, err
+			}
+			if !node.longTypes {
+				node.field.Types = nil
+				node.longTypes = true
+			}
+			node.field.Types = append(node.field.Types, types.TypeFrequency{Type: typeName, FrequencyPercent: percent})
+		}
+	}
+
+	result := &types.ScanResult{Databases: make([]types.Database, 0, len(dbNames))}
+	for _, dbName := range dbNames {
+		db := types.Database{Name: dbName}
+		for _, coll := range collections[dbName] {
+			coll.collection.Fields = csvFields(coll.fields)
+			db.Collections = append(db.Collections, coll.collection)
+		}
+		result.Databases = append(result.Databases, db)
+	}
+	return result, nil
+}
+
+// add attaches a field to its parent: "a.b" is nested under "a", "a[]"
+// holds the elements of "a" and "a[].b" is nested under the elements.
+// Rows whose parent is missing are kept at the top level.
+func (c *csvCollection) add(path string, node *csvNode) {
+	c.nodes[path] = node
+
+	if array, ok := strings.CutSuffix(path, "[]"); ok {
+		if parent, ok := c.nodes[array]; ok {
+			node.field.Path = "[]"
+			parent.items = node
+			return
+		}
+	}
+
+	if i := strings.LastIndex(path, "."); i > 0 {
+		if parent, ok := c.nodes[path[:i]]; ok {
+			node.field.Path = path[i+1:]
+			parent.children = append(parent.children, node)
+			return
+		}
+	}
+
+	node.field.Path = path
+	c.fields = append(c.fields, node)
+}
+
+// csvFields converts rebuilt nodes into fields
+func csvFields(nodes []*csvNode) []types.Field {
+	var fields []types.Field
+	for _, node := range nodes {
+		field := node.field
+		field.NestedFields = csvFields(node.children)
+		if node.items != nil {
+			items := csvFields([]*csvNode{node.items})[0]
+			field.ArrayItems = &items
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// sniffDelimiter picks the most frequent candidate delimiter of the header
+// line
+func sniffDelimiter(data []byte) rune {
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	delimiter, best := ',', 0
+	for _, candidate := range []rune{',', ';', '\t', '|'} {
+		if n := bytes.Count(header, []byte(string(candidate))); n > best {
+			delimiter, best = candidate, n
+		}
+	}
+	return delimiter
+}
@@ -0,0 +1,90 @@
+package loader
+
+import (
+	"bytes"
+	"testing"
+
+	"mongo-scanner/internal/diff"
+	"mongo-scanner/internal/exporter"
+	"mongo-scanner/internal/types"
+)
+
+// scanResult is a scan result whose values survive the one-decimal CSV
+// columns
+func scanResult() *types.ScanResult {
+	return &types.ScanResult{
+		ClusterName: "test",
+		Databases: []types.Database{{
+			Name: "shop",
+			Collections: []types.Collection{{
+				Name:                "orders",
+				DocumentCount:       100,
+				AverageDocSizeBytes: 512,
+				Indexes:             []string{"_id_", "status_1", "customer.email_1"},
+				Fields: []types.Field{
+					{Path: "_id", InferredType: "objectId", PresencePercent: 100, Types: []types.TypeFrequency{{Type: "objectId", FrequencyPercent: 100}}},
+					{Path: "status", InferredType: "string", PresencePercent: 100, Types: []types.TypeFrequency{{Type: "string", FrequencyPercent: 90}, {Type: "null", FrequencyPercent: 10}}},
+					{Path: "items", InferredType: "array", PresencePercent: 80, Types: []types.TypeFrequency{{Type: "array", FrequencyPercent: 100}},
+						ArrayItems: &types.Field{Path: "[]", InferredType: "object", PresencePercent: 75, Types: []types.TypeFrequency{{Type: "object", FrequencyPercent: 100}},
+							NestedFields: []types.Field{
+								{Path: "sku", InferredType: "string", PresencePercent: 100, Types: []types.TypeFrequency{{Type: "string", FrequencyPercent: 100}}},
+								{Path: "qty", InferredType: "mixed", PresencePercent: 60, Types: []types.TypeFrequency{{Type: "int32", FrequencyPercent: 70}, {Type: "double", FrequencyPercent: 30}}},
+							},
+						}},
+				},
+			}},
+		}},
+	}
+}
+
+// roundTrip exports a scan result as CSV and loads it back
+func roundTrip(t *testing.T, result *types.ScanResult, e *exporter.CSVExporter) *types.ScanResult {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := e.Export(result, &b); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	loaded, err := loadCSV(b.Bytes())
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return loaded
+}
+
+func TestCSVLongFormatWithTypeDistribution(t *testing.T) {
+	e := &exporter.CSVExporter{
+		Long:    true,
+		Columns: []string{"database", "collection", "path", "inferred_type", "presence", "type_distribution", "type", "type_percent"},
+	}
+	loaded := roundTrip(t, scanResult(), e)
+
+	status := types.FindField(loaded.Databases[0].Collections[0].Fields, "status")
+	if status == nil {
+		t.Fatal("status field not loaded")
+	}
+	if len(status.Types) != 2 {
+		t.Errorf("status types = %+v, want string and null once each", status.Types)
+	}
+}
+
+func TestCSVRoundTripHasNoChanges(t *testing.T) {
+	tests := map[string]*exporter.CSVExporter{
+		"default columns": {},
+		"with indexes":    {Columns: []string{"database", "collection", "document_count", "avg_doc_size", "path", "inferred_type", "presence", "type_distribution", "indexes"}},
+		"long format":     {Long: true, Delimiter: ';'},
+	}
+	for name, e := range tests {
+		t.Run(name, func(t *testing.T) {
+			original := scanResult()
+			loaded := roundTrip(t, original, e)
+
+			if changes := diff.Compare(original, loaded, diff.DefaultOptions()); len(changes) > 0 {
+				t.Errorf("JSON to CSV changes = %+v, want none", changes)
+			}
+			if changes := diff.Compare(loaded, original, diff.DefaultOptions()); len(changes) > 0 {
+				t.Errorf("CSV to JSON changes = %+v, want none", changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package loader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"gopkg.in/yaml.v3"
+
+	"mongo-scanner/internal/output"
+	"mongo-scanner/internal/types"
+)
+
+// LoadFile reads a scan report previously written by the JSON, YAML or CSV
+// exporter, decompressing files ending in .gz or .zst
+func LoadFile(path string) (*types.ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	compression := output.CompressionExt(path)
+	if compression != "" {
+		if data, err = decompress(data, compression); err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+		}
+	}
+
+	result, err := Load(data, formatFromPath(strings.TrimSuffix(path, compression), data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return result, nil
+}
+
+// Load decodes a scan report in the given format ("json", "yaml" or "csv")
+func Load(data []byte, format string) (*types.ScanResult, error) {
+	var result types.ScanResult
+
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+	case "yaml":
+		if err := yaml.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+	case "csv":
+		return loadCSV(data)
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s", format)
+	}
+
+	return &result, nil
+}
+
+// decompress inflates gzip or zstd data by compression extension
+func decompress(data []byte, compression string) ([]byte, error) {
+	switch strings.ToLower(compression) {
+	case ".gz":
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	case ".zst":
+		r, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	default:
+		return data, nil
+	}
+}
+
+// formatFromPath guesses the report format from the file extension,
+// falling back to sniffing the content
+func formatFromPath(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".csv", ".tsv":
+		return "csv"
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return "json"
+	}
+	if header, _, _ := bytes.Cut(trimmed, []byte("\n")); bytes.Contains(header, []byte(csvPathHeader)) {
+		return "csv"
+	}
+	return "yaml"
+}
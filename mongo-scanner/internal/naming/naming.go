@@ -0,0 +1,179 @@
+package naming
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Style represents a key naming convention
+type Style string
+
+const (
+	StyleCamel          Style = "camelCase"
+	StylePascal         Style = "PascalCase"
+	StyleSnake          Style = "snake_case"
+	StyleScreamingSnake Style = "SCREAMING_SNAKE_CASE"
+	StyleKebab          Style = "kebab-case"
+	StyleLower          Style = "lowercase"
+	StyleUpper          Style = "UPPERCASE"
+	StyleMixed          Style = "mixed"
+)
+
+// DetectStyle classifies a key by naming convention. Leading underscores
+// (as in _id or __v) are ignored.
+func DetectStyle(key string) Style {
+	key = strings.TrimLeft(key, "_")
+	if key == "" {
+		return StyleMixed
+	}
+
+	hasUnderscore := strings.Contains(key, "_")
+	hasDash := strings.Contains(key, "-")
+	hasLower, hasUpper := false, false
+	for _, r := range key {
+		if unicode.IsLower(r) {
+			hasLower = true
+		}
+		if unicode.IsUpper(r) {
+			hasUpper = true
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return StyleMixed
+		}
+	}
+
+	switch {
+	case hasUnderscore && hasDash:
+		return StyleMixed
+	case hasUnderscore && !hasUpper:
+		return StyleSnake
+	case hasUnderscore && !hasLower:
+		return StyleScreamingSnake
+	case hasUnderscore:
+		return StyleMixed
+	case hasDash && !hasUpper:
+		return StyleKebab
+	case hasDash:
+		return StyleMixed
+	case !hasUpper:
+		return StyleLower
+	case !hasLower:
+		return StyleUpper
+	case unicode.IsUpper([]rune(key)[0]):
+		return StylePascal
+	default:
+		return StyleCamel
+	}
+}
+
+// IsMultiWord reports whether a style carries word boundaries. Single-word
+// keys such as "name" are compatible with every convention.
+func IsMultiWord(style Style) bool {
+	return style != StyleLower && style != StyleUpper && style != StyleMixed
+}
+
+// Words splits a key into lowercase words on separators and case boundaries
+func Words(key string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	runes := []rune(key)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// Split "fooBar" before B and "HTTPServer" before S
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+// Normalize reduces a key to a form that ignores case and separators,
+// so createdAt, created_at and CreatedAt all normalize to "createdat"
+func Normalize(key string) string {
+	return strings.Join(Words(key), "")
+}
+
+// Pascal joins the words of a key in PascalCase, so created_at becomes CreatedAt
+func Pascal(key string) string {
+	var b strings.Builder
+	for _, word := range Words(key) {
+		b.WriteString(capitalize(word))
+	}
+	return b.String()
+}
+
+// Camel joins the words of a key in camelCase, so created_at becomes createdAt
+func Camel(key string) string {
+	words := Words(key)
+	if len(words) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(words[0])
+	for _, word := range words[1:] {
+		b.WriteString(capitalize(word))
+	}
+	return b.String()
+}
+
+// Snake joins the words of a key in snake_case, so createdAt becomes created_at
+func Snake(key string) string {
+	return strings.Join(Words(key), "_")
+}
+
+// Singular returns the singular form of a plural English word using simple
+// suffix rules, so users becomes user and categories becomes category
+func Singular(word string) string {
+	lower := strings.ToLower(word)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(word) > 3:
+		return word[:len(word)-3] + matchCase(word[len(word)-3:], "y")
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"),
+		strings.HasSuffix(lower, "is"):
+		return word
+	case strings.HasSuffix(lower, "s") && len(word) > 1:
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
+
+// capitalize upper-cases the first letter of a word
+func capitalize(word string) string {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return ""
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// matchCase returns replacement in upper case when original is upper case
+func matchCase(original, replacement string) string {
+	if strings.ToUpper(original) == original {
+		return strings.ToUpper(replacement)
+	}
+	return replacement
+}
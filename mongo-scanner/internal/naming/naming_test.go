@@ -0,0 +1,50 @@
+package naming
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := map[string][]string{
+		"createdAt":    {"created", "at"},
+		"CreatedAt":    {"created", "at"},
+		"created_at":   {"created", "at"},
+		"created-at":   {"created", "at"},
+		"HTTPServer":   {"http", "server"},
+		"userID":       {"user", "id"},
+		"address2Line": {"address2", "line"},
+		"__v":          {"v"},
+		"a.b c":        {"a", "b", "c"},
+		"_":            nil,
+		"":             nil,
+	}
+	for key, want := range tests {
+		if got := Words(key); !slices.Equal(got, want) {
+			t.Errorf("Words(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := map[string]string{
+		"users":      "user",
+		"categories": "category",
+		"CATEGORIES": "CATEGORY",
+		"addresses":  "address",
+		"boxes":      "box",
+		"matches":    "match",
+		"dishes":     "dish",
+		"status":     "status",
+		"analysis":   "analysis",
+		"class":      "class",
+		"data":       "data",
+		"s":          "s",
+		"":           "",
+	}
+	for word, want := range tests {
+		if got := Singular(word); got != want {
+			t.Errorf("Singular(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// HTTPClient is the client used by HTTP PUT destinations
+var HTTPClient = &http.Client{Timeout: 5 * time.Minute}
+
+func init() {
+	Register("http", openHTTP)
+	Register("https", openHTTP)
+}
+
+// httpWriter spools the output to a temporary file and uploads it with a
+// single PUT request on Commit. This also covers S3-compatible stores
+// through pre-signed URLs.
+type httpWriter struct {
+	*os.File
+	url string
+}
+
+// openHTTP creates the spool file of an HTTP PUT destination
+func openHTTP(location string) (Writer, error) {
+	f, err := os.CreateTemp("", "mongo-scanner-*.upload")
+	if err != nil {
+		return nil, fmt.Errorf("failed to buffer upload to %s: %w", Redact(location), err)
+	}
+	return &httpWriter{File: f, url: location}, nil
+}
+
+// Commit uploads the spooled content
+func (h *httpWriter) Commit() error {
+	defer os.Remove(h.Name())
+	defer h.Close()
+
+	size, err := h.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := h.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, h.url, io.NopCloser(h.File))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		// The client's errors repeat the full URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = Redact(urlErr.URL)
+		}
+		return fmt.Errorf("failed to upload to %s: %w", Redact(h.url), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to upload to %s: %s %s", Redact(h.url), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Abort discards the spooled content
+func (h *httpWriter) Abort() error {
+	h.Close()
+	return os.Remove(h.Name())
+}
@@ -0,0 +1,205 @@
+package output
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Stdout is the location that streams to standard output
+const Stdout = "-"
+
+// Writer receives the content of an output. Nothing is visible at the
+// location until Commit succeeds; Abort discards what was written.
+type Writer interface {
+	io.Writer
+	Commit() error
+	Abort() error
+}
+
+// Opener creates a writer for a location handled by a registered scheme
+type Opener func(location string) (Writer, error)
+
+var (
+	openersMu sync.RWMutex
+	openers   = make(map[string]Opener)
+)
+
+// Register makes locations starting with "<scheme>://" open through opener.
+// Registering a scheme again replaces its opener.
+func Register(scheme string, opener Opener) {
+	openersMu.Lock()
+	defer openersMu.Unlock()
+	openers[strings.ToLower(scheme)] = opener
+}
+
+// Create opens a writer for a location: "-" for stdout, a registered
+// "<scheme>://..." URL, or a local file path. Content is compressed with
+// gzip or zstd when the location ends in .gz or .zst.
+func Create(location string) (Writer, error) {
+	var w Writer
+	var err error
+
+	switch scheme := Scheme(location); {
+	case location == Stdout:
+		w = stdoutWriter{}
+	case scheme != "":
+		openersMu.RLock()
+		opener, ok := openers[scheme]
+		openersMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unsupported output scheme %s://", scheme)
+		}
+		w, err = opener(location)
+	default:
+		w, err = createFile(location)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return compress(w, location)
+}
+
+// Scheme returns the lowercase URL scheme of a location, or "" for local
+// paths. Single-letter schemes are Windows drive letters.
+func Scheme(location string) string {
+	scheme, _, ok := strings.Cut(location, "://")
+	if !ok || len(scheme) < 2 {
+		return ""
+	}
+	for _, r := range scheme {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.') {
+			return ""
+		}
+	}
+	return strings.ToLower(scheme)
+}
+
+// Redact hides the secrets of a location so it can be logged: the query
+// string, which holds the signature of pre-signed URLs, and the password of
+// URLs with credentials. Local paths are returned unchanged.
+func Redact(location string) string {
+	if Scheme(location) == "" {
+		return location
+	}
+
+	base, _, _ := strings.Cut(location, "?")
+	u, err := url.Parse(base)
+	if err != nil {
+		return base
+	}
+	return u.Redacted()
+}
+
+// IsLocal reports whether a location is a local file path
+func IsLocal(location string) bool {
+	return location != Stdout && Scheme(location) == ""
+}
+
+// CompressionExt returns the compression extension of a location as
+// written, ".gz" or ".zst" in any case, or "" when it is not compressed
+func CompressionExt(location string) string {
+	location, _, _ = strings.Cut(location, "?")
+	switch ext := filepath.Ext(location); strings.ToLower(ext) {
+	case ".gz", ".zst":
+		return ext
+	default:
+		return ""
+	}
+}
+
+// compress wraps w in the compressor matching the location's extension
+func compress(w Writer, location string) (Writer, error) {
+	switch strings.ToLower(CompressionExt(location)) {
+	case ".gz":
+		return &compressedWriter{WriteCloser: gzip.NewWriter(w), dest: w}, nil
+	case ".zst":
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			w.Abort()
+			return nil, err
+		}
+		return &compressedWriter{WriteCloser: zw, dest: w}, nil
+	default:
+		return w, nil
+	}
+}
+
+// compressedWriter compresses into another writer
+type compressedWriter struct {
+	io.WriteCloser
+	dest Writer
+}
+
+// Commit flushes the compressed stream and commits the destination
+func (c *compressedWriter) Commit() error {
+	if err := c.WriteCloser.Close(); err != nil {
+		c.dest.Abort()
+		return err
+	}
+	return c.dest.Commit()
+}
+
+// Abort discards the destination
+func (c *compressedWriter) Abort() error {
+	c.WriteCloser.Close()
+	return c.dest.Abort()
+}
+
+// stdoutWriter streams to standard output, which stays open
+type stdoutWriter struct{}
+
+func (stdoutWriter) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+func (stdoutWriter) Commit() error               { return nil }
+func (stdoutWriter) Abort() error                { return nil }
+
+// fileWriter writes a temporary file in the target directory, renamed over
+// the target on Commit so a failed write never leaves a truncated file
+type fileWriter struct {
+	*os.File
+	path string
+}
+
+// createFile creates the temporary file of a local path
+func createFile(path string) (*fileWriter, error) {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	f, err := os.CreateTemp(dir, "."+name+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file %s: %w", path, err)
+	}
+	return &fileWriter{File: f, path: path}, nil
+}
+
+// Commit closes the temporary file and moves it into place
+func (f *fileWriter) Commit() error {
+	err := f.Chmod(0o644)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// Abort removes the temporary file
+func (f *fileWriter) Abort() error {
+	f.Close()
+	return os.Remove(f.Name())
+}
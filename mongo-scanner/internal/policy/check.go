@@ -0,0 +1,273 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"mongo-scanner/internal/diff"
+	"mongo-scanner/internal/schema"
+	"mongo-scanner/internal/types"
+)
+
+// collectionFields holds the flattened fields of the collections of a scan
+// result, by "db.coll"
+type collectionFields map[string]flatCollection
+
+// flatCollection lists the flattened fields of a collection in document
+// order and indexes them by path
+type flatCollection struct {
+	fields []schema.FlatField
+	byPath map[string]schema.FlatField
+}
+
+// Check compares a new scan result to a baseline and reports a finding for
+// each change a rule flags, in rule order
+func Check(baseline, current *types.ScanResult, p *Policy) []types.Finding {
+	changes := diff.Compare(baseline, current, diff.Options{})
+	oldFields := flattenCollections(baseline)
+	newFields := flattenCollections(current)
+
+	var findings []types.Finding
+	for _, rule := range p.Rules {
+		var found []types.Finding
+		switch rule.Kind {
+		case KindRequiredFieldMissing:
+			found = requiredFieldsMissing(rule, oldFields, newFields)
+		case KindNewMixedType:
+			found = newMixedTypes(rule, oldFields, newFields)
+		default:
+			found = p.flaggedChanges(rule, changes, oldFields, newFields)
+		}
+
+		for _, f := range found {
+			if !p.excluded(f) && rule.matches(f.Database, f.Collection) {
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// flaggedChanges returns the findings of rules that flag diff changes
+func (p *Policy) flaggedChanges(rule Rule, changes []types.SchemaChange, oldFields, newFields collectionFields) []types.Finding {
+	var findings []types.Finding
+	for _, c := range changes {
+		switch {
+		case rule.Kind == KindDatabaseRemoved && c.Kind == diff.KindDatabase && c.Change == types.ChangeRemoved,
+			rule.Kind == KindCollectionRemoved && c.Kind == diff.KindCollection && c.Change == types.ChangeRemoved,
+			rule.Kind == KindIndexRemoved && c.Kind == diff.KindIndex && c.Change == types.ChangeRemoved:
+		case rule.Kind == KindTypeChanged && c.Kind == diff.KindInferredType:
+			// Fields becoming mixed-type are left to new-mixed-type when the
+			// policy has that rule
+			if c.To == "mixed" && p.hasRule(KindNewMixedType) {
+				continue
+			}
+		case rule.Kind == KindFieldRemoved && c.Kind == diff.KindField && c.Change == types.ChangeRemoved:
+			old := lookup(oldFields, c.Database, c.Collection, c.Path)
+			if old == nil || old.PresencePercent <= rule.MinPresence {
+				continue
+			}
+			c.Message = fmt.Sprintf("field removed (was %s, %.1f%% presence)", old.InferredType, old.PresencePercent)
+		case rule.Kind == KindPresenceDropped && c.Kind == diff.KindPresence:
+			old := lookup(oldFields, c.Database, c.Collection, c.Path)
+			current := lookup(newFields, c.Database, c.Collection, c.Path)
+			if old == nil || current == nil || old.PresencePercent-current.PresencePercent < rule.MinDrop {
+				continue
+			}
+		default:
+			continue
+		}
+		findings = append(findings, rule.finding(c.Database, c.Collection, c.Path, c.Index, c.Message))
+	}
+	return findings
+}
+
+// newMixedTypes flags fields that are mixed-type in the new scan but were
+// not in the baseline, including fields of new collections
+func newMixedTypes(rule Rule, oldFields, newFields collectionFields) []types.Finding {
+	var findings []types.Finding
+	for _, key := range sortedKeys(newFields) {
+		dbName, collName := splitKey(key)
+		for _, f := range newFields[key].fields {
+			if f.Field.InferredType != "mixed" {
+				continue
+			}
+			old := lookup(oldFields, dbName, collName, f.Path)
+			if old != nil && old.InferredType == "mixed" {
+				continue
+			}
+
+			message := fmt.Sprintf("new mixed-type field (%s)", typeList(f.Field.Types))
+			if old != nil {
+				message = fmt.Sprintf("field became mixed-type: was %s, now %s", old.InferredType, typeList(f.Field.Types))
+			}
+			findings = append(findings, rule.finding(dbName, collName, f.Path, "", message))
+		}
+	}
+	return findings
+}
+
+// requiredFieldsMissing flags fields a validator generated from the
+// baseline would require that are missing from documents of the new scan.
+// Presence is relative to the parent object, as in the validator exports.
+// Collections removed since the baseline are left to collection-removed,
+// and fields whose parent is gone are only reported through the parent.
+func requiredFieldsMissing(rule Rule, oldFields, newFields collectionFields) []types.Finding {
+	var findings []types.Finding
+	for _, key := range sortedKeys(oldFields) {
+		current, ok := newFields[key]
+		if !ok {
+			continue
+		}
+		dbName, collName := splitKey(key)
+
+		for _, f := range oldFields[key].fields {
+			// Validators do not require arrays to have elements
+			if strings.HasSuffix(f.Path, "[]") || !schema.Required(f.Field, f.Parent, rule.RequiredThreshold) {
+				continue
+			}
+
+			var message string
+			field, ok := current.byPath[f.Path]
+			switch {
+			case !ok:
+				if parent := schema.ParentPath(f.Path); parent != "" {
+					if _, ok := current.byPath[parent]; !ok {
+						continue
+					}
+				}
+				message = "field required by the baseline validator no longer appears in sampled documents"
+			case !schema.Required(field.Field, field.Parent, rule.RequiredThreshold):
+				message = fmt.Sprintf("field required by the baseline validator is present in %.1f%% of %s (baseline %.1f%%)", schema.Presence(field.Field, field.Parent), presenceScope(f), schema.Presence(f.Field, f.Parent))
+			default:
+				continue
+			}
+			findings = append(findings, rule.finding(dbName, collName, f.Path, "", message))
+		}
+	}
+	return findings
+}
+
+// presenceScope names what the presence of a flattened field is relative to
+func presenceScope(f schema.FlatField) string {
+	switch {
+	case f.Parent != nil:
+		return "its parent objects"
+	case strings.Contains(f.Path, "[]"):
+		return "array elements"
+	default:
+		return "documents"
+	}
+}
+
+// hasRule reports whether the policy has a rule of a kind
+func (p *Policy) hasRule(kind string) bool {
+	return slices.ContainsFunc(p.Rules, func(r Rule) bool { return r.Kind == kind })
+}
+
+// finding creates a finding of the rule
+func (r Rule) finding(dbName, collName, fieldPath, index, message string) types.Finding {
+	return types.Finding{
+		Rule:       r.ID,
+		Severity:   r.Severity,
+		Database:   dbName,
+		Collection: collName,
+		Path:       fieldPath,
+		Index:      index,
+		Message:    message,
+	}
+}
+
+// matches reports whether the rule applies to a collection. Database-wide
+// findings match when the rule is not restricted to collections.
+func (r Rule) matches(dbName, collName string) bool {
+	if len(r.Collections) == 0 {
+		return true
+	}
+	if collName == "" {
+		return false
+	}
+	for _, pattern := range r.Collections {
+		if ok, _ := path.Match(pattern, dbName+"."+collName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// excluded reports whether a finding's database, collection or field
+// matches an exclude pattern
+func (p *Policy) excluded(f types.Finding) bool {
+	candidates := []string{f.Database}
+	if f.Collection != "" {
+		candidates = append(candidates, f.Database+"."+f.Collection)
+		if f.Path != "" {
+			candidates = append(candidates, f.Database+"."+f.Collection+"."+f.Path)
+		}
+	}
+
+	for _, pattern := range p.Exclude {
+		for _, candidate := range candidates {
+			if ok, _ := path.Match(pattern, candidate); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flattenCollections flattens the fields of every collection of a scan
+// result
+func flattenCollections(result *types.ScanResult) collectionFields {
+	fields := make(collectionFields)
+	for _, db := range result.Databases {
+		for _, coll := range db.Collections {
+			flat := flatCollection{
+				fields: schema.Flatten(coll.Fields),
+				byPath: make(map[string]schema.FlatField),
+			}
+			for _, f := range flat.fields {
+				flat.byPath[f.Path] = f
+			}
+			fields[db.Name+"."+coll.Name] = flat
+		}
+	}
+	return fields
+}
+
+// lookup finds a field of a collection by flattened path
+func lookup(fields collectionFields, dbName, collName, fieldPath string) *types.Field {
+	if f, ok := fields[dbName+"."+collName].byPath[fieldPath]; ok {
+		return &f.Field
+	}
+	return nil
+}
+
+// sortedKeys returns the collection keys in order
+func sortedKeys(fields collectionFields) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitKey splits a "db.coll" key. Database names cannot contain dots,
+// collection names can.
+func splitKey(key string) (string, string) {
+	dbName, collName, _ := strings.Cut(key, ".")
+	return dbName, collName
+}
+
+// typeList formats the observed types of a field, such as "string, int32"
+func typeList(frequencies []types.TypeFrequency) string {
+	names := make([]string, 0, len(frequencies))
+	for _, t := range frequencies {
+		names = append(names, t.Type)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"slices"
+	"testing"
+
+	"mongo-scanner/internal/types"
+)
+
+// field builds a single-type field, or a mixed field of string and int32
+func field(path, typ string, presence float64, nested ...types.Field) types.Field {
+	f := types.Field{Path: path, InferredType: typ, PresencePercent: presence, NestedFields: nested}
+	if typ == "mixed" {
+		f.Types = []types.TypeFrequency{{Type: "string", FrequencyPercent: 60}, {Type: "int32", FrequencyPercent: 40}}
+	} else {
+		f.Types = []types.TypeFrequency{{Type: typ, FrequencyPercent: 100}}
+	}
+	return f
+}
+
+// orders builds a scan result of a shop.orders collection with an _id and
+// the given fields
+func orders(fields ...types.Field) *types.ScanResult {
+	return &types.ScanResult{
+		Databases: []types.Database{{
+			Name: "shop",
+			Collections: []types.Collection{{
+				Name:          "orders",
+				DocumentCount: 100,
+				Indexes:       []string{"_id_"},
+				Fields:        append([]types.Field{field("_id", "objectId", 100)}, fields...),
+			}},
+		}},
+	}
+}
+
+// findingKeys lists findings as "rule path" in report order
+func findingKeys(findings []types.Finding) []string {
+	keys := make([]string, 0, len(findings))
+	for _, f := range findings {
+		keys = append(keys, f.Rule+" "+f.Path)
+	}
+	return keys
+}
+
+func TestCheckDefaultPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline *types.ScanResult
+		current  *types.ScanResult
+		want     []string
+	}{
+		{
+			name:     "field removed above min_presence",
+			baseline: orders(field("status", "string", 100), field("legacy", "string", 60)),
+			current:  orders(field("status", "string", 100)),
+			want:     []string{"field-removed legacy"},
+		},
+		{
+			name:     "field removed below min_presence",
+			baseline: orders(field("status", "string", 100), field("legacy", "string", 40)),
+			current:  orders(field("status", "string", 100)),
+			want:     []string{},
+		},
+		{
+			name:     "type changed",
+			baseline: orders(field("total", "string", 100)),
+			current:  orders(field("total", "double", 100)),
+			want:     []string{"type-changed total"},
+		},
+		{
+			name:     "new mixed-type field",
+			baseline: orders(field("status", "string", 100)),
+			current:  orders(field("status", "string", 100), field("code", "mixed", 30)),
+			want:     []string{"new-mixed-type code"},
+		},
+		{
+			name:     "field becoming mixed is reported once",
+			baseline: orders(field("status", "string", 100)),
+			current:  orders(field("status", "mixed", 100)),
+			want:     []string{"new-mixed-type status"},
+		},
+		{
+			name:     "required field missing under an optional parent",
+			baseline: orders(field("shipping", "object", 50, field("city", "string", 50))),
+			current:  orders(field("shipping", "object", 50, field("city", "string", 40))),
+			want:     []string{"required-field-missing shipping.city"},
+		},
+		{
+			name:     "optional parent keeps its required field",
+			baseline: orders(field("shipping", "object", 50, field("city", "string", 50))),
+			current:  orders(field("shipping", "object", 30, field("city", "string", 30))),
+			want:     []string{},
+		},
+		{
+			name:     "missing fields reported once via the parent",
+			baseline: orders(field("shipping", "object", 100, field("city", "string", 100), field("zip", "string", 100))),
+			current:  orders(),
+			want:     []string{"field-removed shipping", "required-field-missing shipping"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findingKeys(Check(tt.baseline, tt.current, DefaultPolicy()))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTypeChangedToMixedWithoutMixedRule(t *testing.T) {
+	p := &Policy{Rules: []Rule{{Kind: KindTypeChanged}}}
+	p.applyDefaults()
+
+	got := findingKeys(Check(orders(field("status", "string", 100)), orders(field("status", "mixed", 100)), p))
+	if want := []string{"type-changed status"}; !slices.Equal(got, want) {
+		t.Errorf("findings = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"mongo-scanner/internal/types"
+)
+
+// Rule kinds
+const (
+	KindDatabaseRemoved      = "database-removed"
+	KindCollectionRemoved    = "collection-removed"
+	KindIndexRemoved         = "index-removed"
+	KindFieldRemoved         = "field-removed"
+	KindTypeChanged          = "type-changed"
+	KindNewMixedType         = "new-mixed-type"
+	KindPresenceDropped      = "presence-dropped"
+	KindRequiredFieldMissing = "required-field-missing"
+)
+
+// ValidKinds returns the list of valid rule kinds
+func ValidKinds() []string {
+	return []string{
+		KindDatabaseRemoved, KindCollectionRemoved, KindIndexRemoved, KindFieldRemoved,
+		KindTypeChanged, KindNewMixedType, KindPresenceDropped, KindRequiredFieldMissing,
+	}
+}
+
+// kindDescriptions describe what each rule kind flags
+var kindDescriptions = map[string]string{
+	KindDatabaseRemoved:      "Database removed",
+	KindCollectionRemoved:    "Collection removed",
+	KindIndexRemoved:         "Index removed",
+	KindFieldRemoved:         "Field removed",
+	KindTypeChanged:          "Inferred type of a field changed",
+	KindNewMixedType:         "Field became mixed-type",
+	KindPresenceDropped:      "Field presence dropped",
+	KindRequiredFieldMissing: "Field required by the baseline validator is missing",
+}
+
+// Policy decides which schema changes between a baseline and a new scan
+// are violations
+type Policy struct {
+	// FailOn is the severity at or above which violations fail the check
+	FailOn types.Severity `yaml:"fail_on"`
+	// Exclude lists glob patterns of databases, collections ("db.coll") and
+	// fields ("db.coll.path") to ignore
+	Exclude []string `yaml:"exclude"`
+	Rules   []Rule   `yaml:"rules"`
+}
+
+// Rule flags one kind of change. Thresholds only apply to the kinds that
+// mention them.
+type Rule struct {
+	// ID names the rule in reports and defaults to the kind
+	ID       string         `yaml:"id"`
+	Kind     string         `yaml:"kind"`
+	Severity types.Severity `yaml:"severity"`
+	// Description is shown in JUnit and SARIF reports and defaults to a
+	// description of the kind
+	Description string `yaml:"description"`
+	// Collections restricts the rule to collections matching these glob
+	// patterns ("db.coll")
+	Collections []string `yaml:"collections"`
+	// MinPresence makes field-removed only flag fields whose baseline
+	// presence was above this percentage
+	MinPresence float64 `yaml:"min_presence"`
+	// MinDrop is the presence drop, in percentage points, at or above
+	// which presence-dropped flags a field
+	MinDrop float64 `yaml:"min_drop"`
+	// RequiredThreshold is the baseline presence at or above which
+	// required-field-missing treats a field as required, like
+	// --required-threshold of the validator exports
+	RequiredThreshold float64 `yaml:"required_threshold"`
+}
+
+// DefaultPolicy returns the policy used when no policy file is given
+func DefaultPolicy() *Policy {
+	p := &Policy{
+		Rules: []Rule{
+			{Kind: KindDatabaseRemoved, Severity: types.SeverityError},
+			{Kind: KindCollectionRemoved, Severity: types.SeverityError},
+			{Kind: KindFieldRemoved, Severity: types.SeverityError, MinPresence: 50},
+			{Kind: KindTypeChanged, Severity: types.SeverityError},
+			{Kind: KindRequiredFieldMissing, Severity: types.SeverityError},
+			{Kind: KindNewMixedType, Severity: types.SeverityWarning},
+			{Kind: KindIndexRemoved, Severity: types.SeverityWarning},
+		},
+	}
+	p.applyDefaults()
+	return p
+}
+
+// LoadFile reads a YAML policy file
+func LoadFile(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy %s: %w", file, err)
+	}
+
+	var p Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", file, err)
+	}
+
+	p.applyDefaults()
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	return &p, nil
+}
+
+// applyDefaults fills in rule IDs, severities, descriptions and thresholds
+func (p *Policy) applyDefaults() {
+	if p.FailOn == "" {
+		p.FailOn = types.SeverityError
+	}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.ID == "" {
+			rule.ID = rule.Kind
+		}
+		if rule.Severity == "" {
+			rule.Severity = types.SeverityError
+		}
+		if rule.Description == "" {
+			rule.Description = kindDescriptions[rule.Kind]
+		}
+		if rule.Kind == KindPresenceDropped && rule.MinDrop == 0 {
+			rule.MinDrop = 10
+		}
+		if rule.Kind == KindRequiredFieldMissing && rule.RequiredThreshold == 0 {
+			rule.RequiredThreshold = 100
+		}
+	}
+}
+
+// Validate checks rule kinds, severities, IDs and glob patterns
+func (p *Policy) Validate() error {
+	if !validSeverity(p.FailOn) {
+		return fmt.Errorf("invalid fail_on severity: %s", p.FailOn)
+	}
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("no rules")
+	}
+	for _, pattern := range p.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	ids := make(map[string]bool)
+	for _, rule := range p.Rules {
+		if !slices.Contains(ValidKinds(), rule.Kind) {
+			return fmt.Errorf("rule %s: invalid kind %q. Valid kinds: %v", rule.ID, rule.Kind, ValidKinds())
+		}
+		if !validSeverity(rule.Severity) {
+			return fmt.Errorf("rule %s: invalid severity: %s", rule.ID, rule.Severity)
+		}
+		if ids[rule.ID] {
+			return fmt.Errorf("duplicate rule id: %s", rule.ID)
+		}
+		ids[rule.ID] = true
+		for _, pattern := range rule.Collections {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %s: invalid collection pattern %q: %w", rule.ID, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validSeverity checks a severity name
+func validSeverity(s types.Severity) bool {
+	switch s {
+	case types.SeverityInfo, types.SeverityWarning, types.SeverityError:
+		return true
+	}
+	return false
+}
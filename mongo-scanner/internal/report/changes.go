@@ -0,0 +1,144 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"mongo-scanner/internal/types"
+)
+
+// ChangeSummary counts schema changes per change type
+type ChangeSummary struct {
+	Added    int `json:"added"`
+	Removed  int `json:"removed"`
+	Changed  int `json:"changed"`
+	Breaking int `json:"breaking"`
+}
+
+// SummarizeChanges counts schema changes per change type
+func SummarizeChanges(changes []types.SchemaChange) ChangeSummary {
+	var summary ChangeSummary
+	for _, c := range changes {
+		switch c.Change {
+		case types.ChangeAdded:
+			summary.Added++
+		case types.ChangeRemoved:
+			summary.Removed++
+		default:
+			summary.Changed++
+		}
+		if c.Breaking {
+			summary.Breaking++
+		}
+	}
+	return summary
+}
+
+// String formats the counts of a summary
+func (s ChangeSummary) String() string {
+	return fmt.Sprintf("%d added, %d removed, %d changed (%d breaking)", s.Added, s.Removed, s.Changed, s.Breaking)
+}
+
+// WriteChanges renders the changes between two scan reports, named from
+// and to, in the requested format
+func WriteChanges(w io.Writer, title, from, to string, changes []types.SchemaChange, format Format) error {
+	switch format {
+	case FormatText:
+		return writeChangesText(w, from, to, changes)
+	case FormatJSON:
+		return writeChangesJSON(w, from, to, changes)
+	case FormatMarkdown:
+		return writeChangesMarkdown(w, title, from, to, changes)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// changeMarkers prefix text lines like a unified diff
+var changeMarkers = map[types.ChangeType]string{
+	types.ChangeAdded:    "+",
+	types.ChangeRemoved:  "-",
+	types.ChangeModified: "~",
+}
+
+// writeChangesText renders one change per line followed by a summary
+func writeChangesText(w io.Writer, from, to string, changes []types.SchemaChange) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", from, to)
+	for _, c := range changes {
+		fmt.Fprintf(&b, "%s %-40s %s", changeMarkers[c.Change], changeLocation(c), c.Message)
+		if c.Breaking {
+			b.WriteString(" [breaking]")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%s\n", SummarizeChanges(changes))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeChangesJSON renders changes and their summary as a JSON document
+func writeChangesJSON(w io.Writer, from, to string, changes []types.SchemaChange) error {
+	if changes == nil {
+		changes = []types.SchemaChange{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		From    string               `json:"from"`
+		To      string               `json:"to"`
+		Summary ChangeSummary        `json:"summary"`
+		Changes []types.SchemaChange `json:"changes"`
+	}{
+		From:    from,
+		To:      to,
+		Summary: SummarizeChanges(changes),
+		Changes: changes,
+	})
+}
+
+// writeChangesMarkdown renders changes as a Markdown table, suitable for
+// pull request comments and release notes
+func writeChangesMarkdown(w io.Writer, title, from, to string, changes []types.SchemaChange) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "`%s` → `%s`\n\n", from, to)
+	fmt.Fprintf(&b, "**%s**\n\n", SummarizeChanges(changes))
+
+	if len(changes) == 0 {
+		b.WriteString("No schema changes.\n")
+	} else {
+		b.WriteString("| Change | Location | Details |\n")
+		b.WriteString("|--------|----------|---------|\n")
+		for _, c := range changes {
+			details := escapeCell(c.Message)
+			if c.Breaking {
+				details = "**breaking:** " + details
+			}
+			fmt.Fprintf(&b, "| %s | `%s` | %s |\n", c.Change, escapeCell(changeLocation(c)), details)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// changeLocation formats where a change applies
+func changeLocation(c types.SchemaChange) string {
+	loc := c.Database
+	if c.Collection != "" {
+		loc += "." + c.Collection
+	}
+	if c.Index != "" {
+		loc += " index " + c.Index
+	}
+	if c.Path != "" {
+		loc += " " + c.Path
+	}
+	return loc
+}
@@ -0,0 +1,170 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"mongo-scanner/internal/types"
+)
+
+// Format represents the output format of a findings report
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "markdown"
+	FormatJUnit    Format = "junit"
+	FormatSARIF    Format = "sarif"
+)
+
+// ValidFormats returns list of valid findings report formats
+func ValidFormats() []string {
+	return []string{string(FormatText), string(FormatJSON), string(FormatMarkdown), string(FormatJUnit), string(FormatSARIF)}
+}
+
+// ChangeFormats returns list of valid schema change report formats
+func ChangeFormats() []string {
+	return []string{string(FormatText), string(FormatJSON), string(FormatMarkdown)}
+}
+
+// Summary counts findings per severity
+type Summary struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Info     int `json:"info"`
+}
+
+// Summarize counts findings per severity
+func Summarize(findings []types.Finding) Summary {
+	var summary Summary
+	for _, f := range findings {
+		switch f.Severity {
+		case types.SeverityError:
+			summary.Errors++
+		case types.SeverityWarning:
+			summary.Warnings++
+		default:
+			summary.Info++
+		}
+	}
+	return summary
+}
+
+// MaxSeverity returns the most important severity among findings
+func MaxSeverity(findings []types.Finding) types.Severity {
+	highest := types.Severity("")
+	for _, f := range findings {
+		if highest == "" || f.Severity.Rank() > highest.Rank() {
+			highest = f.Severity
+		}
+	}
+	return highest
+}
+
+// WriteFindings renders findings in the requested format. failOn is the
+// severity that fails the run, empty when none does, and decides which
+// findings are JUnit failures.
+func WriteFindings(w io.Writer, title string, findings []types.Finding, format Format, failOn types.Severity) error {
+	switch format {
+	case FormatText:
+		return writeText(w, findings)
+	case FormatJSON:
+		return writeJSON(w, findings)
+	case FormatMarkdown:
+		return writeMarkdown(w, title, findings)
+	case FormatJUnit:
+		return WriteJUnit(w, title, findingRules(findings), findings, failOn)
+	case FormatSARIF:
+		return WriteSARIF(w, "", findingRules(findings), findings)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// writeText renders one finding per line followed by a summary
+func writeText(w io.Writer, findings []types.Finding) error {
+	for _, f := range findings {
+		line := fmt.Sprintf("%-7s %-20s %s", strings.ToUpper(string(f.Severity)), f.Rule, location(f))
+		if _, err := fmt.Fprintf(w, "%s: %s\n", line, f.Message); err != nil {
+			return err
+		}
+		if f.Suggestion != "" {
+			if _, err := fmt.Fprintf(w, "        suggestion: %s\n", f.Suggestion); err != nil {
+				return err
+			}
+		}
+	}
+
+	summary := Summarize(findings)
+	_, err := fmt.Fprintf(w, "%d errors, %d warnings, %d info\n", summary.Errors, summary.Warnings, summary.Info)
+	return err
+}
+
+// writeJSON renders findings and their summary as a JSON document
+func writeJSON(w io.Writer, findings []types.Finding) error {
+	if findings == nil {
+		findings = []types.Finding{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Summary  Summary         `json:"summary"`
+		Findings []types.Finding `json:"findings"`
+	}{
+		Summary:  Summarize(findings),
+		Findings: findings,
+	})
+}
+
+// writeMarkdown renders findings as a Markdown table
+func writeMarkdown(w io.Writer, title string, findings []types.Finding) error {
+	var b strings.Builder
+	summary := Summarize(findings)
+
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "**%d errors, %d warnings, %d info**\n\n", summary.Errors, summary.Warnings, summary.Info)
+
+	if len(findings) > 0 {
+		b.WriteString("| Severity | Rule | Location | Message | Suggestion |\n")
+		b.WriteString("|----------|------|----------|---------|------------|\n")
+		for _, f := range findings {
+			fmt.Fprintf(&b, "| %s | `%s` | %s | %s | %s |\n",
+				f.Severity, f.Rule, escapeCell(location(f)), escapeCell(f.Message), escapeCell(f.Suggestion))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// location formats where a finding applies
+func location(f types.Finding) string {
+	var parts []string
+	if f.Database != "" {
+		parts = append(parts, f.Database)
+	}
+	if f.Collection != "" {
+		parts = append(parts, f.Collection)
+	}
+	loc := strings.Join(parts, ".")
+	if loc == "" {
+		loc = "(cluster)"
+	}
+	if f.Index != "" {
+		loc += " index " + f.Index
+	}
+	if f.Path != "" {
+		loc += " " + f.Path
+	}
+	return loc
+}
+
+// escapeCell makes text safe to place inside a Markdown table cell
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
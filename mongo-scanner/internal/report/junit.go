@@ -0,0 +1,119 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"slices"
+
+	"mongo-scanner/internal/types"
+)
+
+// Rule describes a rule findings are reported for, listed in JUnit and
+// SARIF reports even when it has no findings
+type Rule struct {
+	ID          string
+	Description string
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of a rule
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a finding, or the passing check of a rule without
+// findings
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure describes why a test case failed
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit renders findings as a JUnit XML report with one test suite
+// per rule. Findings at or above the failOn severity are failed test cases,
+// so the report agrees with the exit status; an empty failOn fails none.
+// Other findings pass with their message as output, and rules without
+// findings get a single passing test case.
+func WriteJUnit(w io.Writer, title string, rules []Rule, findings []types.Finding, failOn types.Severity) error {
+	report := junitTestSuites{Name: title}
+	for _, rule := range withFindingRules(rules, findings) {
+		suite := junitTestSuite{Name: rule.ID}
+		for _, f := range findings {
+			if f.Rule != rule.ID {
+				continue
+			}
+
+			testCase := junitTestCase{Name: location(f), ClassName: rule.ID}
+			if failOn != "" && f.Severity.Rank() >= failOn.Rank() {
+				text := f.Message
+				if f.Suggestion != "" {
+					text += "\nsuggestion: " + f.Suggestion
+				}
+				testCase.Failure = &junitFailure{Message: f.Message, Type: string(f.Severity), Text: text}
+				suite.Failures++
+			} else {
+				testCase.SystemOut = f.Message
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		if len(suite.Cases) == 0 {
+			name := rule.Description
+			if name == "" {
+				name = rule.ID
+			}
+			suite.Cases = append(suite.Cases, junitTestCase{Name: name, ClassName: rule.ID})
+		}
+
+		suite.Tests = len(suite.Cases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// findingRules lists the rules of findings in order of appearance
+func findingRules(findings []types.Finding) []Rule {
+	return withFindingRules(nil, findings)
+}
+
+// withFindingRules appends the rules of findings missing from rules
+func withFindingRules(rules []Rule, findings []types.Finding) []Rule {
+	rules = slices.Clone(rules)
+	for _, f := range findings {
+		if !slices.ContainsFunc(rules, func(r Rule) bool { return r.ID == f.Rule }) {
+			rules = append(rules, Rule{ID: f.Rule})
+		}
+	}
+	return rules
+}
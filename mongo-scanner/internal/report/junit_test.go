@@ -0,0 +1,62 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"mongo-scanner/internal/types"
+)
+
+func TestWriteJUnitFailures(t *testing.T) {
+	rules := []Rule{{ID: "field-removed"}, {ID: "new-mixed-type"}, {ID: "presence-dropped"}}
+	findings := []types.Finding{
+		{Rule: "field-removed", Severity: types.SeverityError, Database: "shop", Collection: "orders", Path: "legacy", Message: "field removed"},
+		{Rule: "new-mixed-type", Severity: types.SeverityWarning, Database: "shop", Collection: "orders", Path: "code", Message: "new mixed-type field"},
+		{Rule: "presence-dropped", Severity: types.SeverityInfo, Database: "shop", Collection: "orders", Path: "note", Message: "presence dropped"},
+	}
+
+	tests := []struct {
+		failOn   types.Severity
+		failures int
+	}{
+		{"", 0},
+		{types.SeverityInfo, 3},
+		{types.SeverityWarning, 2},
+		{types.SeverityError, 1},
+	}
+	for _, tt := range tests {
+		t.Run("fail on "+string(tt.failOn), func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteJUnit(&b, "Schema Check", rules, findings, tt.failOn); err != nil {
+				t.Fatalf("WriteJUnit: %v", err)
+			}
+
+			var report junitTestSuites
+			if err := xml.Unmarshal(b.Bytes(), &report); err != nil {
+				t.Fatalf("invalid JUnit XML: %v", err)
+			}
+			if report.Tests != 3 {
+				t.Errorf("tests = %d, want 3", report.Tests)
+			}
+			if report.Failures != tt.failures {
+				t.Errorf("failures = %d, want %d", report.Failures, tt.failures)
+			}
+
+			failed := 0
+			for _, suite := range report.Suites {
+				for _, c := range suite.Cases {
+					switch {
+					case c.Failure != nil:
+						failed++
+					case c.SystemOut == "":
+						t.Errorf("passing finding %s has no output", c.Name)
+					}
+				}
+			}
+			if failed != tt.failures {
+				t.Errorf("failed test cases = %d, want %d", failed, tt.failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"mongo-scanner/internal/types"
+	"mongo-scanner/internal/version"
+)
+
+// sarifSchema is the JSON schema of SARIF 2.1.0 logs
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifLog is the root object of a SARIF log
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun is the output of a single run of a tool
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes the tool that produced a run
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver is the tool component with the rules
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+// sarifRule describes a rule results refer to
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+// sarifResult is a single finding
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+// sarifMessage is a plain text message
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifLocation locates a result
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+// sarifPhysicalLocation locates a result in a file
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+// sarifArtifactLocation is the path of a file
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifRegion is a region of a file
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifLogicalLocation locates a result in the schema, such as db.coll.path
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevels maps finding severities to SARIF result levels
+var sarifLevels = map[types.Severity]string{
+	types.SeverityError:   "error",
+	types.SeverityWarning: "warning",
+	types.SeverityInfo:    "note",
+}
+
+// WriteSARIF renders findings as a SARIF 2.1.0 log. Findings are located
+// by their database, collection and field as logical locations, and in
+// artifact, the file the findings were made in, when it is not empty, so
+// that code scanning tools can show them.
+func WriteSARIF(w io.Writer, artifact string, rules []Rule, findings []types.Finding) error {
+	rules = withFindingRules(rules, findings)
+	driver := sarifDriver{Name: "mongo-scanner", Version: version.String(), Rules: make([]sarifRule, 0, len(rules))}
+	ruleIndex := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		description := rule.Description
+		if description == "" {
+			description = rule.ID
+		}
+		driver.Rules = append(driver.Rules, sarifRule{ID: rule.ID, ShortDescription: sarifMessage{Text: description}})
+		ruleIndex[rule.ID] = i
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		text := f.Message
+		if f.Suggestion != "" {
+			text += " Suggestion: " + f.Suggestion
+		}
+
+		loc := sarifLocation{LogicalLocations: []sarifLogicalLocation{{
+			FullyQualifiedName: qualifiedName(f),
+			Kind:               "member",
+		}}}
+		if artifact != "" {
+			loc.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: strings.TrimPrefix(artifact, "./")},
+				Region:           sarifRegion{StartLine: 1},
+			}
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    f.Rule,
+			RuleIndex: ruleIndex[f.Rule],
+			Level:     sarifLevels[f.Severity],
+			Message:   sarifMessage{Text: text},
+			Locations: []sarifLocation{loc},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
+
+// qualifiedName joins the database, collection, index and field of a
+// finding with dots
+func qualifiedName(f types.Finding) string {
+	var parts []string
+	for _, part := range []string{f.Database, f.Collection, f.Index, f.Path} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "(cluster)"
+	}
+	return strings.Join(parts, ".")
+}
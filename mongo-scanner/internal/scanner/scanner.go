@@ -3,7 +3,11 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"os"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +18,7 @@ import (
 	"mongo-scanner/internal/analyzer"
 	"mongo-scanner/internal/logger"
 	"mongo-scanner/internal/types"
+	"mongo-scanner/internal/version"
 )
 
 // Scanner handles MongoDB schema scanning
@@ -65,13 +70,17 @@ func (s *Scanner) ScanAll(ctx context.Context) (*types.ScanResult, error) {
 	}
 
 	// Filter databases if specified
-	databases = s.filterDatabases(databases)
+	databases = s.FilterDatabases(databases)
 
 	s.log.Info("Found %d databases to scan", len(databases))
 
 	result := &types.ScanResult{
+		SchemaVersion: types.SchemaVersion,
 		ClusterName:   clusterName,
-		ScanTimestamp: time.Now().UTC().Format(time.RFC3339),
+		ScanTimestamp: scanTimestamp(),
+		Scanner:       &types.ScannerInfo{Name: "mongo-scanner", Version: version.String()},
+		Server:        s.serverInfo(ctx),
+		Options:       s.settings(),
 		Databases:     make([]types.Database, 0, len(databases)),
 	}
 
@@ -106,11 +115,79 @@ func (s *Scanner) ScanAll(ctx context.Context) (*types.ScanResult, error) {
 	}
 
 	wg.Wait()
+	// Each database sorted its own collections and fields
+	sort.Slice(result.Databases, func(i, j int) bool {
+		return result.Databases[i].Name < result.Databases[j].Name
+	})
 
 	s.log.Info("Scan completed. Processed %d databases", len(result.Databases))
 	return result, nil
 }
 
+// scanTimestamp returns the current time, or the time in SOURCE_DATE_EPOCH
+// when set so that reproducible builds of a report keep a fixed timestamp
+func scanTimestamp() string {
+	now := time.Now()
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		if seconds, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+			now = time.Unix(seconds, 0)
+		}
+	}
+	return now.UTC().Format(time.RFC3339)
+}
+
+// serverInfo reads the server version and topology. Missing privileges
+// leave the corresponding fields empty.
+func (s *Scanner) serverInfo(ctx context.Context) *types.ServerInfo {
+	info := &types.ServerInfo{}
+	admin := s.client.Database("admin")
+
+	var build struct {
+		Version string `bson:"version"`
+	}
+	if err := admin.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&build); err != nil {
+		s.log.Warn("Could not get server version: %v", err)
+	}
+	info.Version = build.Version
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		// Servers before 4.4.2 only know the legacy command
+		err = admin.RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&hello)
+	}
+	switch {
+	case err != nil:
+		s.log.Warn("Could not get server topology: %v", err)
+	case hello.Msg == "isdbgrid":
+		info.Topology = "sharded"
+	case hello.SetName != "":
+		info.Topology = "replica_set"
+	default:
+		info.Topology = "standalone"
+	}
+
+	return info
+}
+
+// settings returns the effective scan options as recorded in the result
+func (s *Scanner) settings() *types.ScanSettings {
+	return &types.ScanSettings{
+		URI:            MaskURI(s.options.URI),
+		TimeoutSeconds: int(s.options.Timeout / time.Second),
+		MaxDocs:        s.options.MaxDocs,
+		DBFilter:       s.options.DBFilter,
+		Concurrency:    s.options.Concurrency,
+		DriftField:     s.options.DriftField,
+		DriftWindow:    s.options.DriftWindow,
+		Associations:   s.options.Associations,
+		Enums:          s.options.Enums,
+	}
+}
+
 // ScanDatabase scans a specific database
 func (s *Scanner) ScanDatabase(ctx context.Context, dbName string) (*types.Database, error) {
 	s.log.Info("Scanning database: %s", dbName)
@@ -177,6 +254,7 @@ func (s *Scanner) ScanDatabase(ctx context.Context, dbName string) (*types.Datab
 	}
 
 	wg.Wait()
+	database.Sort()
 
 	return database, nil
 }
@@ -198,14 +276,14 @@ func (s *Scanner) ScanCollection(ctx context.Context, dbName, collName string) (
 	sampleSize := s.calculateSampleSize(docCount)
 
 	// Get indexes
-	indexes, err := s.getIndexes(ctx, coll)
+	indexes, indexDetails, err := s.getIndexes(ctx, coll)
 	if err != nil {
 		s.log.Warn("Could not get indexes for %s.%s: %v", dbName, collName, err)
 		indexes = []string{}
 	}
 
 	// Sample documents
-	docs, err := s.sampleDocuments(ctx, coll, sampleSize)
+	docs, sizes, err := s.sampleDocuments(ctx, coll, sampleSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sample documents from %s.%s: %w", dbName, collName, err)
 	}
@@ -213,25 +291,40 @@ func (s *Scanner) ScanCollection(ctx context.Context, dbName, collName string) (
 	s.log.Debug("Sampled %d documents from %s.%s", len(docs), dbName, collName)
 
 	// Analyze documents
-	analysis := analyzer.AnalyzeDocuments(docs)
-
-	// Calculate average doc size
-	avgDocSize := int64(0)
-	if len(docs) > 0 {
-		totalSize := int64(0)
-		for _, doc := range docs {
-			data, _ := bson.Marshal(doc)
-			totalSize += int64(len(data))
-		}
-		avgDocSize = totalSize / int64(len(docs))
+	analysis := analyzer.AnalyzeDocuments(docs, analyzer.AnalyzeOptions{Enums: s.options.Enums})
+
+	// Calculate document size, depth and key count distributions
+	docStats := analyzer.AnalyzeDocumentStats(docs, sizes)
+	if docStats.NearLimitCount > 0 {
+		s.log.Warn("%d sampled documents in %s.%s are near the 16 MB limit", docStats.NearLimitCount, dbName, collName)
 	}
 
 	collection := &types.Collection{
 		Name:                collName,
 		DocumentCount:       docCount,
-		AverageDocSizeBytes: avgDocSize,
+		AverageDocSizeBytes: int64(docStats.SizeBytes.Mean),
 		Indexes:             indexes,
+		IndexDetails:        indexDetails,
 		Fields:              analysis.Fields,
+		DocumentStats:       docStats,
+	}
+
+	// Analyze schema drift over time if requested
+	if s.options.DriftField != "" {
+		drift, err := analyzer.AnalyzeDrift(docs, analyzer.DriftOptions{
+			Field:  s.options.DriftField,
+			Window: s.options.DriftWindow,
+		})
+		if err != nil {
+			s.log.Warn("Could not analyze drift for %s.%s: %v", dbName, collName, err)
+		} else {
+			collection.Drift = drift
+		}
+	}
+
+	// Analyze field co-occurrence if requested
+	if s.options.Associations {
+		collection.FieldAssociations = analyzer.AnalyzeAssociations(docs, analyzer.DefaultAssociationOptions())
 	}
 
 	return collection, nil
@@ -265,8 +358,9 @@ func (s *Scanner) calculateSampleSize(docCount int64) int {
 	}
 }
 
-// sampleDocuments fetches sample documents from a collection
-func (s *Scanner) sampleDocuments(ctx context.Context, coll *mongo.Collection, sampleSize int) ([]bson.M, error) {
+// sampleDocuments fetches sample documents from a collection along with
+// the raw BSON size of each document
+func (s *Scanner) sampleDocuments(ctx context.Context, coll *mongo.Collection, sampleSize int) ([]bson.M, []int, error) {
 	// Use aggregation with $sample for random sampling
 	pipeline := mongo.Pipeline{
 		{{Key: "$sample", Value: bson.D{{Key: "size", Value: sampleSize}}}},
@@ -279,43 +373,113 @@ func (s *Scanner) sampleDocuments(ctx context.Context, coll *mongo.Collection, s
 		findOpts := options.Find().SetLimit(int64(sampleSize))
 		cursor, err = coll.Find(ctx, bson.M{}, findOpts)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 	defer cursor.Close(ctx)
 
 	var docs []bson.M
-	if err := cursor.All(ctx, &docs); err != nil {
-		return nil, err
+	var sizes []int
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, nil, err
+		}
+		docs = append(docs, doc)
+		sizes = append(sizes, len(cursor.Current))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, nil, err
 	}
 
-	return docs, nil
+	return docs, sizes, nil
 }
 
-// getIndexes retrieves index names from a collection
-func (s *Scanner) getIndexes(ctx context.Context, coll *mongo.Collection) ([]string, error) {
+// indexSpec is the subset of an index specification returned by listIndexes
+type indexSpec struct {
+	Name                    string      `bson:"name"`
+	Key                     bson.D      `bson:"key"`
+	Unique                  bool        `bson:"unique"`
+	Sparse                  bool        `bson:"sparse"`
+	ExpireAfterSeconds      interface{} `bson:"expireAfterSeconds"`
+	PartialFilterExpression bson.Raw    `bson:"partialFilterExpression"`
+}
+
+// getIndexes retrieves index names and definitions from a collection
+func (s *Scanner) getIndexes(ctx context.Context, coll *mongo.Collection) ([]string, []types.Index, error) {
 	cursor, err := coll.Indexes().List(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer cursor.Close(ctx)
 
 	var indexes []string
+	var details []types.Index
 	for cursor.Next(ctx) {
-		var idx bson.M
-		if err := cursor.Decode(&idx); err != nil {
+		var spec indexSpec
+		if err := cursor.Decode(&spec); err != nil {
+			name, _ := cursor.Current.Lookup("name").StringValueOK()
+			s.log.Warn("Could not decode index %q of %s.%s: %v", name, coll.Database().Name(), coll.Name(), err)
 			continue
 		}
-		if name, ok := idx["name"].(string); ok {
-			indexes = append(indexes, name)
+		if spec.Name == "" {
+			continue
 		}
+		indexes = append(indexes, spec.Name)
+
+		index := types.Index{
+			Name:   spec.Name,
+			Keys:   make([]types.IndexKey, 0, len(spec.Key)),
+			Unique: spec.Unique,
+			Sparse: spec.Sparse,
+		}
+		for _, elem := range spec.Key {
+			index.Keys = append(index.Keys, types.IndexKey{
+				Field: elem.Key,
+				Order: indexOrder(elem.Value),
+			})
+		}
+		if ttl, ok := toInt64(spec.ExpireAfterSeconds); ok {
+			index.ExpireAfterSeconds = &ttl
+		}
+		if len(spec.PartialFilterExpression) > 0 {
+			index.PartialFilter = spec.PartialFilterExpression.String()
+		}
+		details = append(details, index)
 	}
 
-	return indexes, nil
+	return indexes, details, nil
 }
 
-// filterDatabases applies the database filter
-func (s *Scanner) filterDatabases(databases []string) []string {
+// indexOrder formats an index key value such as 1, -1 or "text"
+func indexOrder(val interface{}) string {
+	if n, ok := toInt64(val); ok {
+		if n < 0 {
+			return "-1"
+		}
+		return "1"
+	}
+	return fmt.Sprint(val)
+}
+
+// toInt64 converts a BSON numeric value to int64
+func toInt64(val interface{}) (int64, bool) {
+	switch v := val.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	case int:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// FilterDatabases returns the databases matching the database filter
+func (s *Scanner) FilterDatabases(databases []string) []string {
 	if len(s.options.DBFilter) == 0 {
 		return databases
 	}
@@ -349,3 +513,21 @@ func (s *Scanner) extractClusterName() string {
 	}
 	return "unknown"
 }
+
+// MaskURI hides the credentials of a connection URI
+func MaskURI(uri string) string {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return uri
+	}
+
+	authority := rest
+	if i := strings.IndexAny(rest, "/?"); i >= 0 {
+		authority = rest[:i]
+	}
+	at := strings.LastIndex(authority, "@")
+	if at < 0 {
+		return uri
+	}
+	return scheme + "://****:****" + rest[at:]
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ValidatorState is the validation configuration of a collection
+type ValidatorState struct {
+	Exists           bool
+	Validator        bson.M
+	ValidationLevel  string
+	ValidationAction string
+}
+
+// collectionInfo is the subset of listCollections output used for validators
+type collectionInfo struct {
+	Options struct {
+		Validator        bson.M `bson:"validator"`
+		ValidationLevel  string `bson:"validationLevel"`
+		ValidationAction string `bson:"validationAction"`
+	} `bson:"options"`
+}
+
+// GetValidator returns the current validator of a collection
+func (s *Scanner) GetValidator(ctx context.Context, dbName, collName string) (*ValidatorState, error) {
+	cursor, err := s.client.Database(dbName).ListCollections(ctx, bson.D{{Key: "name", Value: collName}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection %s.%s: %w", dbName, collName, err)
+	}
+	defer cursor.Close(ctx)
+
+	state := &ValidatorState{}
+	if cursor.Next(ctx) {
+		var info collectionInfo
+		if err := cursor.Decode(&info); err != nil {
+			return nil, fmt.Errorf("failed to decode collection info for %s.%s: %w", dbName, collName, err)
+		}
+		state.Exists = true
+		state.Validator = info.Options.Validator
+		state.ValidationLevel = info.Options.ValidationLevel
+		state.ValidationAction = info.Options.ValidationAction
+	}
+
+	return state, cursor.Err()
+}
+
+// ApplyValidator sets a collection's validator with collMod
+func (s *Scanner) ApplyValidator(ctx context.Context, dbName, collName string, validator interface{}, level, action string) error {
+	cmd := bson.D{
+		{Key: "collMod", Value: collName},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: level},
+		{Key: "validationAction", Value: action},
+	}
+
+	if err := s.client.Database(dbName).RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("collMod failed for %s.%s: %w", dbName, collName, err)
+	}
+
+	return nil
+}
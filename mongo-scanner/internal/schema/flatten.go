@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mongo-scanner/internal/types"
+)
+
+// FlatField is a field listed by its full path, such as "items[].sku", with
+// its nesting depth. Parent is the object field it is nested in, nil for
+// top-level fields, array elements and their fields.
+type FlatField struct {
+	Path   string
+	Depth  int
+	Field  types.Field
+	Parent *types.Field
+}
+
+// Flatten lists a field tree in document order by full path. Nested fields
+// follow their parent and array elements are listed as "path[]", followed by
+// their element fields. These are the paths of the CSV "Field Path" column.
+func Flatten(fields []types.Field) []FlatField {
+	return flatten(fields, "", 0, nil)
+}
+
+// flatten lists the fields of parent with paths below prefix
+func flatten(fields []types.Field, prefix string, depth int, parent *types.Field) []FlatField {
+	var flat []FlatField
+	for i, field := range fields {
+		path := field.Path
+		if prefix != "" {
+			path = prefix + "." + field.Path
+		}
+		flat = append(flat, FlatField{Path: path, Depth: depth, Field: field, Parent: parent})
+		flat = append(flat, flatten(field.NestedFields, path, depth+1, &fields[i])...)
+
+		itemsPath, itemsDepth := path, depth
+		for items := field.ArrayItems; items != nil; items = items.ArrayItems {
+			itemsPath += "[]"
+			itemsDepth++
+			flat = append(flat, FlatField{Path: itemsPath, Depth: itemsDepth, Field: *items})
+			flat = append(flat, flatten(items.NestedFields, itemsPath, itemsDepth+1, nil)...)
+		}
+	}
+	return flat
+}
+
+// ParentPath returns the flattened path of the field containing a path: "a"
+// for "a.b" and "a[]", "a[]" for "a[].b", and "" for top-level fields
+func ParentPath(path string) string {
+	if parent, ok := strings.CutSuffix(path, "[]"); ok {
+		return parent
+	}
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// TypeDistribution formats the observed types of a field, such as
+// "string:90.0%, null:10.0%"
+func TypeDistribution(frequencies []types.TypeFrequency) string {
+	parts := make([]string, 0, len(frequencies))
+	for _, t := range frequencies {
+		parts = append(parts, fmt.Sprintf("%s:%.1f%%", t.Type, t.FrequencyPercent))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseTypeDistribution parses a type distribution written by
+// TypeDistribution
+func ParseTypeDistribution(s string) ([]types.TypeFrequency, error) {
+	var frequencies []types.TypeFrequency
+	for _, part := range strings.Split(s, ", ") {
+		i := strings.LastIndex(part, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid type distribution %q", s)
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(part[i+1:], "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type distribution %q", s)
+		}
+		frequencies = append(frequencies, types.TypeFrequency{Type: part[:i], FrequencyPercent: percent})
+	}
+	return frequencies, nil
+}
@@ -0,0 +1,80 @@
+// Package schema holds the rules shared by the exporters, code generators,
+// diff and policy checks for reading analyzed fields: requiredness,
+// nullability, types and the flat field paths, so every output reads a scan
+// report the same way.
+package schema
+
+import (
+	"fmt"
+	"math"
+
+	"mongo-scanner/internal/types"
+)
+
+// Required reports whether a field's presence within its parent object
+// reaches the required threshold. parent is the object field f is nested in,
+// or nil for top-level fields.
+func Required(f types.Field, parent *types.Field, threshold float64) bool {
+	return Presence(f, parent) >= threshold
+}
+
+// Presence returns the percentage of a field's parent objects that hold the
+// field. The analyzer reports nested fields relative to the documents holding
+// the top-level field, so a field always present in an optional object would
+// otherwise never count as required.
+func Presence(f types.Field, parent *types.Field) float64 {
+	if parent == nil || parent.PresencePercent <= 0 {
+		return f.PresencePercent
+	}
+	return math.Min(100, math.Round(f.PresencePercent/parent.PresencePercent*10000)/100)
+}
+
+// Elements returns the element schema of an array field under a new path,
+// for walking it like an object field. The presence of element fields is
+// already relative to the elements, so the elements count as always present.
+func Elements(f types.Field, path string) types.Field {
+	items := *f.ArrayItems
+	items.Path = path
+	items.PresencePercent = 100
+	return items
+}
+
+// Nullable reports whether null values were observed for a field
+func Nullable(f types.Field) bool {
+	for _, t := range f.Types {
+		if t.Type == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// Types returns the non-null BSON types a schema should allow for a field:
+// the inferred type, or every observed type when the field is mixed
+func Types(f types.Field) []string {
+	switch f.InferredType {
+	case "mixed":
+		var observed []string
+		for _, t := range f.Types {
+			if t.Type != "null" {
+				observed = append(observed, t.Type)
+			}
+		}
+		return observed
+	case "null", "unknown", "":
+		return nil
+	default:
+		return []string{f.InferredType}
+	}
+}
+
+// UniqueName returns name, or name with a numeric suffix when it is taken,
+// and marks the result as taken
+func UniqueName(name string, taken map[string]bool) string {
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[candidate] = true
+	return candidate
+}
@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"testing"
+
+	"mongo-scanner/internal/types"
+)
+
+func TestPresenceAndRequired(t *testing.T) {
+	address := types.Field{Path: "address", InferredType: "object", PresencePercent: 50}
+	empty := types.Field{Path: "address", InferredType: "object", PresencePercent: 0}
+	items := types.Field{Path: "items", InferredType: "array", PresencePercent: 40,
+		ArrayItems: &types.Field{Path: "[]", InferredType: "object", PresencePercent: 90}}
+	item := Elements(items, "item")
+
+	tests := []struct {
+		name     string
+		field    types.Field
+		parent   *types.Field
+		presence float64
+		required bool
+	}{
+		{"top-level field", types.Field{PresencePercent: 100}, nil, 100, true},
+		{"optional top-level field", types.Field{PresencePercent: 99.5}, nil, 99.5, false},
+		{"always present in an optional object", types.Field{PresencePercent: 50}, &address, 100, true},
+		{"sometimes present in an optional object", types.Field{PresencePercent: 25}, &address, 50, false},
+		{"rounded to two decimals", types.Field{PresencePercent: 100.0 / 3}, &address, 66.67, false},
+		{"capped at 100", types.Field{PresencePercent: 60}, &address, 100, true},
+		{"parent never seen", types.Field{PresencePercent: 30}, &empty, 30, false},
+		{"always present in array elements", types.Field{PresencePercent: 100}, &item, 100, true},
+		{"sometimes present in array elements", types.Field{PresencePercent: 80}, &item, 80, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Presence(tt.field, tt.parent); got != tt.presence {
+				t.Errorf("Presence = %v, want %v", got, tt.presence)
+			}
+			if got := Required(tt.field, tt.parent, 100); got != tt.required {
+				t.Errorf("Required = %v, want %v", got, tt.required)
+			}
+		})
+	}
+}
+
+func TestElements(t *testing.T) {
+	items := types.Field{Path: "items", InferredType: "array", PresencePercent: 40,
+		ArrayItems: &types.Field{Path: "[]", InferredType: "object", PresencePercent: 90}}
+
+	item := Elements(items, "item")
+	if item.Path != "item" || item.InferredType != "object" || item.PresencePercent != 100 {
+		t.Errorf("Elements = %+v, want an always present object named item", item)
+	}
+	if items.ArrayItems.Path != "[]" || items.ArrayItems.PresencePercent != 90 {
+		t.Errorf("Elements changed the array items: %+v", *items.ArrayItems)
+	}
+}
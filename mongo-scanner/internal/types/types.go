@@ -2,17 +2,95 @@ package types
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// ScanResult represents the complete scan output
+// SchemaVersion is the version of the scan result format. The minor version
+// is bumped for additive changes and the major version for breaking ones.
+const SchemaVersion = "1.1"
+
+// ScanResult represents the complete scan output. Reports written before
+// the format was versioned have no SchemaVersion, Scanner, Server or Options.
 type ScanResult struct {
-	ClusterName   string     `json:"cluster_name" yaml:"cluster_name"`
-	ScanTimestamp string     `json:"scan_timestamp" yaml:"scan_timestamp"`
-	Databases     []Database `json:"databases" yaml:"databases"`
+	SchemaVersion string        `json:"schema_version,omitempty" yaml:"schema_version,omitempty"`
+	ClusterName   string        `json:"cluster_name" yaml:"cluster_name"`
+	ScanTimestamp string        `json:"scan_timestamp" yaml:"scan_timestamp"`
+	Scanner       *ScannerInfo  `json:"scanner,omitempty" yaml:"scanner,omitempty"`
+	Server        *ServerInfo   `json:"server,omitempty" yaml:"server,omitempty"`
+	Options       *ScanSettings `json:"options,omitempty" yaml:"options,omitempty"`
+	Databases     []Database    `json:"databases" yaml:"databases"`
+}
+
+// ScannerInfo identifies the tool that produced a scan result
+type ScannerInfo struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// ServerInfo describes the scanned deployment. Topology is "standalone",
+// "replica_set" or "sharded".
+type ServerInfo struct {
+	Version  string `json:"version,omitempty" yaml:"version,omitempty"`
+	Topology string `json:"topology,omitempty" yaml:"topology,omitempty"`
+}
+
+// ScanSettings records the effective scan options, with the credentials of
+// the URI masked
+type ScanSettings struct {
+	URI            string   `json:"uri" yaml:"uri"`
+	TimeoutSeconds int      `json:"timeout_seconds" yaml:"timeout_seconds"`
+	MaxDocs        int      `json:"max_docs" yaml:"max_docs"`
+	DBFilter       []string `json:"db_filter,omitempty" yaml:"db_filter,omitempty"`
+	Concurrency    int      `json:"concurrency" yaml:"concurrency"`
+	DriftField     string   `json:"drift_field,omitempty" yaml:"drift_field,omitempty"`
+	DriftWindow    string   `json:"drift_window,omitempty" yaml:"drift_window,omitempty"`
+	Associations   bool     `json:"associations,omitempty" yaml:"associations,omitempty"`
+	Enums          bool     `json:"enums,omitempty" yaml:"enums,omitempty"`
+}
+
+// Sort orders databases and collections by name and fields by path, so
+// that scans of the same data serialize identically regardless of the
+// order in which collections finished scanning
+func (r *ScanResult) Sort() {
+	sort.Slice(r.Databases, func(i, j int) bool {
+		return r.Databases[i].Name < r.Databases[j].Name
+	})
+	for i := range r.Databases {
+		r.Databases[i].Sort()
+	}
+}
+
+// Sort orders the collections of a database by name and their fields by
+// path
+func (d *Database) Sort() {
+	sort.Slice(d.Collections, func(i, j int) bool {
+		return d.Collections[i].Name < d.Collections[j].Name
+	})
+	for i := range d.Collections {
+		sortFields(d.Collections[i].Fields, true)
+	}
+}
+
+// sortFields orders a field tree by path like the analyzer does, with _id
+// first among top-level fields
+func sortFields(fields []Field, idFirst bool) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		if idFirst && (fields[i].Path == "_id" || fields[j].Path == "_id") {
+			return fields[i].Path == "_id" && fields[j].Path != "_id"
+		}
+		return fields[i].Path < fields[j].Path
+	})
+	for i := range fields {
+		sortFields(fields[i].NestedFields, false)
+		for items := fields[i].ArrayItems; items != nil; items = items.ArrayItems {
+			sortFields(items.NestedFields, false)
+		}
+	}
 }
 
 // Database represents a MongoDB database schema
@@ -24,20 +102,57 @@ type Database struct {
 
 // Collection represents a MongoDB collection schema
 type Collection struct {
-	Name                string   `json:"name" yaml:"name"`
-	DocumentCount       int64    `json:"document_count" yaml:"document_count"`
-	AverageDocSizeBytes int64    `json:"average_doc_size_bytes" yaml:"average_doc_size_bytes"`
-	Indexes             []string `json:"indexes" yaml:"indexes"`
-	Fields              []Field  `json:"fields" yaml:"fields"`
+	Name                string             `json:"name" yaml:"name"`
+	DocumentCount       int64              `json:"document_count" yaml:"document_count"`
+	AverageDocSizeBytes int64              `json:"average_doc_size_bytes" yaml:"average_doc_size_bytes"`
+	Indexes             []string           `json:"indexes" yaml:"indexes"`
+	IndexDetails        []Index            `json:"index_details,omitempty" yaml:"index_details,omitempty"`
+	IndexesUnknown      bool               `json:"indexes_unknown,omitempty" yaml:"indexes_unknown,omitempty"`
+	Fields              []Field            `json:"fields" yaml:"fields"`
+	Drift               *SchemaDrift       `json:"drift,omitempty" yaml:"drift,omitempty"`
+	FieldAssociations   []FieldAssociation `json:"field_associations,omitempty" yaml:"field_associations,omitempty"`
+	DocumentStats       *DocumentStats     `json:"document_stats,omitempty" yaml:"document_stats,omitempty"`
+}
+
+// Index describes an index definition
+type Index struct {
+	Name               string     `json:"name" yaml:"name"`
+	Keys               []IndexKey `json:"keys" yaml:"keys"`
+	Unique             bool       `json:"unique,omitempty" yaml:"unique,omitempty"`
+	Sparse             bool       `json:"sparse,omitempty" yaml:"sparse,omitempty"`
+	PartialFilter      string     `json:"partial_filter,omitempty" yaml:"partial_filter,omitempty"`
+	ExpireAfterSeconds *int64     `json:"expire_after_seconds,omitempty" yaml:"expire_after_seconds,omitempty"`
+}
+
+// IndexKey is a single field of an index key pattern. Order is "1" or "-1"
+// for ascending/descending keys, or the index type (text, hashed, 2dsphere...)
+type IndexKey struct {
+	Field string `json:"field" yaml:"field"`
+	Order string `json:"order" yaml:"order"`
 }
 
-// Field represents a document field with type information
+// Reference is a field inferred to hold the _id of another document
+type Reference struct {
+	Database         string `json:"database" yaml:"database"`
+	Collection       string `json:"collection" yaml:"collection"`
+	Path             string `json:"path" yaml:"path"`
+	TargetCollection string `json:"target_collection,omitempty" yaml:"target_collection,omitempty"`
+	Many             bool   `json:"many,omitempty" yaml:"many,omitempty"`
+}
+
+// Field represents a document field with type information.
+// For arrays, ArrayItems describes the elements: Path is "[]", PresencePercent
+// is the share of non-empty arrays, and NestedFields holds the element field
+// tree when elements are objects. Enum lists the distinct values of
+// low-cardinality string fields.
 type Field struct {
 	Path            string          `json:"path" yaml:"path"`
 	Types           []TypeFrequency `json:"types" yaml:"types"`
 	InferredType    string          `json:"inferred_type" yaml:"inferred_type"`
 	PresencePercent float64         `json:"presence_percent" yaml:"presence_percent"`
+	Enum            []string        `json:"enum,omitempty" yaml:"enum,omitempty"`
 	NestedFields    []Field         `json:"nested_fields,omitempty" yaml:"nested_fields,omitempty"`
+	ArrayItems      *Field          `json:"array_items,omitempty" yaml:"array_items,omitempty"`
 }
 
 // TypeFrequency represents a BSON type and its frequency
@@ -46,6 +161,150 @@ type TypeFrequency struct {
 	FrequencyPercent float64 `json:"frequency_percent" yaml:"frequency_percent"`
 }
 
+// SchemaDrift describes how a collection's document shape changed over time
+type SchemaDrift struct {
+	BucketField         string        `json:"bucket_field" yaml:"bucket_field"`
+	Window              string        `json:"window" yaml:"window"`
+	UnbucketedDocuments int           `json:"unbucketed_documents" yaml:"unbucketed_documents"`
+	Windows             []DriftWindow `json:"windows" yaml:"windows"`
+	Fields              []FieldDrift  `json:"fields" yaml:"fields"`
+	Events              []DriftEvent  `json:"events,omitempty" yaml:"events,omitempty"`
+}
+
+// DriftWindow is a single time bucket of sampled documents
+type DriftWindow struct {
+	Label         string `json:"label" yaml:"label"`
+	Start         string `json:"start" yaml:"start"`
+	DocumentCount int    `json:"document_count" yaml:"document_count"`
+}
+
+// FieldDrift tracks a field path across time windows
+type FieldDrift struct {
+	Path      string             `json:"path" yaml:"path"`
+	FirstSeen string             `json:"first_seen" yaml:"first_seen"`
+	LastSeen  string             `json:"last_seen" yaml:"last_seen"`
+	Windows   []FieldWindowStats `json:"windows" yaml:"windows"`
+}
+
+// FieldWindowStats holds the presence and type mix of a field within one window
+type FieldWindowStats struct {
+	Label           string          `json:"label" yaml:"label"`
+	PresencePercent float64         `json:"presence_percent" yaml:"presence_percent"`
+	Types           []TypeFrequency `json:"types" yaml:"types"`
+}
+
+// DriftEvent is a notable change in a field between windows
+type DriftEvent struct {
+	Window  string `json:"window" yaml:"window"`
+	Path    string `json:"path" yaml:"path"`
+	Kind    string `json:"kind" yaml:"kind"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// DocumentStats holds per-collection distributions of document shape
+type DocumentStats struct {
+	SampleSize              int               `json:"sample_size" yaml:"sample_size"`
+	SizeBytes               Distribution      `json:"size_bytes" yaml:"size_bytes"`
+	Depth                   Distribution      `json:"depth" yaml:"depth"`
+	KeyCount                Distribution      `json:"key_count" yaml:"key_count"`
+	NearLimitCount          int               `json:"near_limit_count" yaml:"near_limit_count"`
+	NearLimitThresholdBytes int64             `json:"near_limit_threshold_bytes" yaml:"near_limit_threshold_bytes"`
+	Outliers                []DocumentOutlier `json:"outliers" yaml:"outliers"`
+}
+
+// Distribution summarizes a numeric sample
+type Distribution struct {
+	Min  int64   `json:"min" yaml:"min"`
+	P50  int64   `json:"p50" yaml:"p50"`
+	P95  int64   `json:"p95" yaml:"p95"`
+	P99  int64   `json:"p99" yaml:"p99"`
+	Max  int64   `json:"max" yaml:"max"`
+	Mean float64 `json:"mean" yaml:"mean"`
+}
+
+// DocumentOutlier identifies an unusually large or deep document
+type DocumentOutlier struct {
+	ID        string   `json:"id" yaml:"id"`
+	SizeBytes int64    `json:"size_bytes" yaml:"size_bytes"`
+	Depth     int      `json:"depth" yaml:"depth"`
+	KeyCount  int      `json:"key_count" yaml:"key_count"`
+	Reasons   []string `json:"reasons" yaml:"reasons"`
+}
+
+// FieldAssociation describes a strong presence relationship between two fields.
+// For "present_when" associations, Related is the condition field and Value
+// the condition value; counts are then relative to documents matching it.
+type FieldAssociation struct {
+	Kind         string  `json:"kind" yaml:"kind"`
+	Field        string  `json:"field" yaml:"field"`
+	Related      string  `json:"related" yaml:"related"`
+	Value        string  `json:"value,omitempty" yaml:"value,omitempty"`
+	FieldCount   int     `json:"field_count" yaml:"field_count"`
+	RelatedCount int     `json:"related_count" yaml:"related_count"`
+	BothCount    int     `json:"both_count" yaml:"both_count"`
+	Confidence   float64 `json:"confidence_percent" yaml:"confidence_percent"`
+	Message      string  `json:"message" yaml:"message"`
+}
+
+// Severity represents the importance of a finding
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Rank orders severities from least to most important
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Finding is a single issue reported by an analysis over a scan result.
+// Cluster-wide findings leave Database and Collection empty.
+type Finding struct {
+	Rule       string   `json:"rule" yaml:"rule"`
+	Severity   Severity `json:"severity" yaml:"severity"`
+	Database   string   `json:"database,omitempty" yaml:"database,omitempty"`
+	Collection string   `json:"collection,omitempty" yaml:"collection,omitempty"`
+	Path       string   `json:"path,omitempty" yaml:"path,omitempty"`
+	Index      string   `json:"index,omitempty" yaml:"index,omitempty"`
+	Message    string   `json:"message" yaml:"message"`
+	Suggestion string   `json:"suggestion,omitempty" yaml:"suggestion,omitempty"`
+}
+
+// ChangeType tells whether a schema change adds, removes or modifies something
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "changed"
+)
+
+// SchemaChange is a single difference between two scan results. From and To
+// hold the old and new values of modifications. Breaking changes may break
+// applications reading the data, such as removed fields or type changes.
+type SchemaChange struct {
+	Kind       string     `json:"kind" yaml:"kind"`
+	Change     ChangeType `json:"change" yaml:"change"`
+	Database   string     `json:"database" yaml:"database"`
+	Collection string     `json:"collection,omitempty" yaml:"collection,omitempty"`
+	Path       string     `json:"path,omitempty" yaml:"path,omitempty"`
+	Index      string     `json:"index,omitempty" yaml:"index,omitempty"`
+	From       string     `json:"from,omitempty" yaml:"from,omitempty"`
+	To         string     `json:"to,omitempty" yaml:"to,omitempty"`
+	Breaking   bool       `json:"breaking,omitempty" yaml:"breaking,omitempty"`
+	Message    string     `json:"message" yaml:"message"`
+}
+
 // ScanOptions contains configuration for the scanner
 type ScanOptions struct {
 	URI         string
@@ -54,6 +313,11 @@ type ScanOptions struct {
 	DBFilter    []string
 	Verbose     bool
 	Concurrency int
+	DriftField  string
+	DriftWindow string
+
+	Associations bool
+	Enums        bool
 }
 
 // DefaultScanOptions returns default scanning options
@@ -73,6 +337,93 @@ type CollectionAnalysis struct {
 	RareFields       []string
 }
 
+// IndexDefinitions returns the collection's index definitions. Reports written
+// before index details were captured only hold index names, so definitions are
+// then derived from default index names such as "email_1" or "a_1_b_-1".
+func (c Collection) IndexDefinitions() []Index {
+	if len(c.IndexDetails) > 0 {
+		return c.IndexDetails
+	}
+
+	var indexes []Index
+	for _, name := range c.Indexes {
+		if keys := ParseIndexName(name); len(keys) > 0 {
+			indexes = append(indexes, Index{Name: name, Keys: keys})
+		}
+	}
+	return indexes
+}
+
+// ParseIndexName derives the key pattern from a default index name.
+// It returns nil when the name does not follow the default naming scheme.
+func ParseIndexName(name string) []IndexKey {
+	if name == "_id_" {
+		return []IndexKey{{Field: "_id", Order: "1"}}
+	}
+
+	var keys []IndexKey
+	var field []string
+	for _, token := range strings.Split(name, "_") {
+		switch token {
+		case "1", "-1", "text", "hashed", "2d", "2dsphere":
+			if len(field) == 0 {
+				return nil
+			}
+			keys = append(keys, IndexKey{Field: strings.Join(field, "_"), Order: token})
+			field = nil
+		default:
+			field = append(field, token)
+		}
+	}
+
+	if len(field) > 0 {
+		return nil
+	}
+	return keys
+}
+
+// FindField looks up a field by dotted path in a field tree
+func FindField(fields []Field, path string) *Field {
+	for i := range fields {
+		if fields[i].Path == path {
+			return &fields[i]
+		}
+	}
+
+	for i := range fields {
+		prefix := fields[i].Path + "."
+		if strings.HasPrefix(path, prefix) {
+			remaining := strings.TrimPrefix(path, prefix)
+			if f := FindField(fields[i].NestedFields, remaining); f != nil {
+				return f
+			}
+			// Dot notation reaches into array elements
+			for items := fields[i].ArrayItems; items != nil; items = items.ArrayItems {
+				if f := FindField(items.NestedFields, remaining); f != nil {
+					return f
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// CountFields recursively counts fields, including nested fields and the
+// fields of array elements
+func CountFields(fields []Field) int {
+	count := len(fields)
+	for _, f := range fields {
+		if len(f.NestedFields) > 0 {
+			count += CountFields(f.NestedFields)
+		}
+		for items := f.ArrayItems; items != nil; items = items.ArrayItems {
+			count += CountFields(items.NestedFields)
+		}
+	}
+	return count
+}
+
 // GetBSONTypeName returns the string name of a BSON type
 func GetBSONTypeName(val interface{}) string {
 	if val == nil {
@@ -0,0 +1,112 @@
+package types
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+// sortedResult returns a scan result whose databases, collections and field
+// trees are already in the order Sort produces
+func sortedResult() *ScanResult {
+	element := func(paths ...string) []Field {
+		fields := make([]Field, len(paths))
+		for i, path := range paths {
+			fields[i] = Field{Path: path, InferredType: "string", PresencePercent: 100}
+		}
+		return fields
+	}
+
+	fields := []Field{
+		{Path: "_id", InferredType: "objectId", PresencePercent: 100},
+		{Path: "address", InferredType: "object", PresencePercent: 90, NestedFields: element("city", "street", "zip")},
+		{Path: "items", InferredType: "array", PresencePercent: 100, ArrayItems: &Field{
+			Path: "[]", InferredType: "object", PresencePercent: 95,
+			NestedFields: []Field{
+				{Path: "dimensions", InferredType: "object", PresencePercent: 50, NestedFields: element("depth", "height", "width")},
+				{Path: "qty", InferredType: "int32", PresencePercent: 100},
+				{Path: "sku", InferredType: "string", PresencePercent: 100},
+			},
+		}},
+		{Path: "matrix", InferredType: "array", PresencePercent: 40, ArrayItems: &Field{
+			Path: "[]", InferredType: "array", PresencePercent: 100, ArrayItems: &Field{
+				Path: "[]", InferredType: "object", PresencePercent: 100, NestedFields: element("x", "y", "z"),
+			},
+		}},
+		{Path: "name", InferredType: "string", PresencePercent: 100},
+	}
+
+	collection := func(name string) Collection {
+		return Collection{Name: name, DocumentCount: 10, Fields: deepCopy(fields)}
+	}
+
+	return &ScanResult{
+		ClusterName: "test",
+		Databases: []Database{
+			{Name: "analytics", Collections: []Collection{collection("events"), collection("sessions")}},
+			{Name: "shop", Collections: []Collection{collection("customers"), collection("orders"), collection("products")}},
+		},
+	}
+}
+
+// deepCopy copies a field tree so shuffling one copy leaves the others alone
+func deepCopy(fields []Field) []Field {
+	if fields == nil {
+		return nil
+	}
+	out := make([]Field, len(fields))
+	for i, f := range fields {
+		out[i] = f
+		out[i].NestedFields = deepCopy(f.NestedFields)
+		if f.ArrayItems != nil {
+			items := deepCopy([]Field{*f.ArrayItems})[0]
+			out[i].ArrayItems = &items
+		}
+	}
+	return out
+}
+
+// shuffleFields shuffles a field tree at every level, including the element
+// fields of arrays
+func shuffleFields(rng *rand.Rand, fields []Field) {
+	rng.Shuffle(len(fields), func(i, j int) { fields[i], fields[j] = fields[j], fields[i] })
+	for i := range fields {
+		shuffleFields(rng, fields[i].NestedFields)
+		for items := fields[i].ArrayItems; items != nil; items = items.ArrayItems {
+			shuffleFields(rng, items.NestedFields)
+		}
+	}
+}
+
+func TestSortIsDeterministic(t *testing.T) {
+	want, err := json.Marshal(sortedResult())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		result := sortedResult()
+		rng.Shuffle(len(result.Databases), func(i, j int) {
+			result.Databases[i], result.Databases[j] = result.Databases[j], result.Databases[i]
+		})
+		for _, db := range result.Databases {
+			rng.Shuffle(len(db.Collections), func(i, j int) {
+				db.Collections[i], db.Collections[j] = db.Collections[j], db.Collections[i]
+			})
+			for _, coll := range db.Collections {
+				shuffleFields(rng, coll.Fields)
+			}
+		}
+
+		result.Sort()
+
+		got, err := json.Marshal(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Fatalf("shuffle %d: sorted JSON differs\ngot:  %s\nwant: %s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package version
+
+import "runtime/debug"
+
+// Version is the release version of mongo-scanner, set at build time with
+// -ldflags "-X mongo-scanner/internal/version.Version=v1.2.3"
+var Version = ""
+
+// String returns the release version, the module version when installed
+// with go install, or "dev" for local builds
+func String() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}